@@ -1,24 +1,58 @@
 package cmd
 
 import (
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/alecthomas/kong"
+	"github.com/monochromegane/beacon/internal/adapter"
 	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/config"
 	"github.com/monochromegane/beacon/internal/context"
+	"github.com/monochromegane/beacon/internal/notify"
+	"github.com/monochromegane/beacon/internal/process"
+	"github.com/monochromegane/beacon/internal/query"
+	"github.com/monochromegane/beacon/internal/render"
+	"github.com/monochromegane/beacon/internal/storage"
 )
 
 const cmdName = "beacon"
 
 type EmitCmd struct {
-	ID      string `name:"id" required:"" help:"Session identifier"`
-	Message string `arg:"" help:"Message to emit"`
-	Context string `name:"context" short:"c" help:"Context type (tmux)" enum:",tmux" default:""`
+	ID        string        `name:"id" xor:"id" help:"Session identifier (default: $BEACON_ID, then the variable named by id.env)"`
+	Message   string        `arg:"" optional:"" help:"Message to emit (optional with --from-json)"`
+	Context   string        `name:"context" short:"c" help:"Context type (none, tmux; default: emit.context)" enum:",none,tmux" default:"" env:"BEACON_CONTEXT"`
+	Status    string        `name:"status" short:"s" help:"Agent status (running, waiting-for-input, blocked, done, failed)" enum:"running,waiting-for-input,blocked,done,failed" default:"waiting-for-input"`
+	Force     bool          `name:"force" help:"Accept status transitions that are not normally allowed"`
+	TTL       time.Duration `name:"ttl" help:"Expire the beacon after this duration (e.g. 30m)" xor:"expiry"`
+	ExpiresAt time.Time     `name:"expires-at" help:"Expire the beacon at this RFC 3339 time" xor:"expiry"`
+	PID       int           `name:"pid" help:"PID of the agent process owning the beacon (default: parent process)"`
+	Labels    []string      `name:"label" short:"l" help:"Label as key=value, e.g. project=web (repeatable)" sep:"none"`
+
+	FromJSON        bool   `name:"from-json" help:"Read a JSON object from stdin and take the beacon from it"`
+	IDPath          string `name:"id-path" xor:"id" help:"Path to the session identifier in the JSON input (e.g. .session.id)"`
+	MessagePath     string `name:"message-path" xor:"message" help:"Path to the message in the JSON input (e.g. .msg)"`
+	MessageTemplate string `name:"message-template" xor:"message" help:"Go text/template applied to the JSON input to build the message (e.g. '{{.repo}}: {{.msg}}')"`
+	SilenceIf       string `name:"silence-if" help:"Silence the beacon instead when this path in the JSON input is true (e.g. .done)"`
+}
+
+// Validate rejects a negative --ttl, which would expire the beacon before
+// it is written.
+func (c *EmitCmd) Validate() error {
+	if c.TTL < 0 {
+		return fmt.Errorf("--ttl must not be negative: %s", c.TTL)
+	}
+	return nil
 }
 
 func (c *EmitCmd) Run(cli *CLI) error {
@@ -26,20 +60,76 @@ func (c *EmitCmd) Run(cli *CLI) error {
 	if err != nil {
 		return err
 	}
+	id, message := c.ID, c.Message
+	if c.FromJSON {
+		mapping := adapter.Mapping{IDPath: c.IDPath, MessagePath: c.MessagePath, MessageTemplate: c.MessageTemplate, SilenceIf: c.SilenceIf}
+		action, err := mapping.Parse(cli.in)
+		if err != nil {
+			return err
+		}
+		id = cmp.Or(action.ID, id)
+		if action.Op == adapter.OpSilence {
+			if id, err = cli.resolveID(id); err != nil {
+				return err
+			}
+			return b.Silence(id)
+		}
+		if c.MessagePath != "" || c.MessageTemplate != "" {
+			message = action.Message
+		}
+	} else if c.IDPath != "" || c.MessagePath != "" || c.MessageTemplate != "" || c.SilenceIf != "" {
+		return errors.New("--id-path, --message-path, --message-template and --silence-if need --from-json")
+	}
+	if message == "" && c.MessagePath == "" && c.MessageTemplate == "" {
+		return errors.New("missing message: pass it as an argument or, with --from-json, --message-path or --message-template")
+	}
+	if id, err = cli.resolveID(id); err != nil {
+		return err
+	}
+
+	status, err := beacon.ParseStatus(c.Status)
+	if err != nil {
+		return err
+	}
+	labels, err := beacon.ParseLabels(c.Labels)
+	if err != nil {
+		return err
+	}
+	pid := c.PID
+	if pid == 0 {
+		pid = os.Getppid()
+	}
+	emission := beacon.Emission{
+		Message: message,
+		Status:  status,
+		Force:   c.Force,
+		Owner:   process.NewProcFS().Identify(pid),
+		Labels:  labels,
+	}
+	if c.TTL > 0 {
+		emission.ExpiresAt = time.Now().Add(c.TTL)
+	} else if !c.ExpiresAt.IsZero() {
+		emission.ExpiresAt = c.ExpiresAt
+	}
 
-	if c.Context == "" {
-		return b.Emit(c.ID, c.Message)
+	contextType := c.Context
+	if contextType == "" {
+		contextType = cli.config.Emit.Context
+	}
+	if contextType == "" || contextType == "none" {
+		return b.Emit(id, emission)
 	}
 
-	ctx, err := cli.getContext(c.Context)
+	ctx, err := cli.getContext(contextType)
 	if err != nil {
 		return err
 	}
-	return b.EmitWithContext(c.ID, c.Message, ctx)
+	return b.EmitWithContext(id, emission, ctx)
 }
 
 type SilenceCmd struct {
-	ID string `name:"id" required:"" help:"Session identifier"`
+	ID       string   `name:"id" xor:"target" help:"Session identifier (default: $BEACON_ID, then the variable named by id.env)"`
+	Selector []string `name:"selector" short:"l" xor:"target" help:"Silence all beacons whose labels match (e.g. project=web,kind!=approval)"`
 }
 
 func (c *SilenceCmd) Run(cli *CLI) error {
@@ -47,17 +137,174 @@ func (c *SilenceCmd) Run(cli *CLI) error {
 	if err != nil {
 		return err
 	}
-	return b.Silence(c.ID)
+	if len(c.Selector) == 0 {
+		id, err := cli.resolveID(c.ID)
+		if err != nil {
+			return err
+		}
+		return b.Silence(id)
+	}
+	selector, err := beacon.ParseSelector(c.Selector)
+	if err != nil {
+		return err
+	}
+	_, err = b.SilenceSelected(selector)
+	return err
 }
 
-type ListCmd struct{}
+type ListCmd struct {
+	ReapDead    bool     `name:"reap-dead" help:"Remove beacons whose owner process has exited before listing"`
+	WithContext bool     `name:"with-context" help:"Include the context of each beacon, such as its tmux pane"`
+	Selector    []string `name:"selector" short:"l" help:"Only list beacons whose labels match (e.g. project=web,agent)"`
+	Filter      string   `name:"filter" help:"Only list beacons matching the expression (e.g. 'status = waiting-for-input and age > 5m')" default:""`
+	Sort        []string `name:"sort" help:"Sort by fields, prefixed with - for descending order (e.g. --sort=-age,id)"`
+	Limit       int      `name:"limit" help:"List at most this many beacons (0 for no limit)"`
+	ExitCode    bool     `name:"exit-code" help:"Exit with status 1 if any beacon is listed, 0 if none is and 2 on errors"`
+	Format      string   `name:"format" short:"f" help:"Output format (table, json, ndjson, tsv; default: list.format or table)" enum:",table,json,ndjson,tsv" default:"" env:"BEACON_LIST_FORMAT"`
+	Template    string   `name:"template" short:"t" help:"Go text/template string applied to each beacon (overrides --format)" default:""`
+	AllProfiles bool     `name:"all-profiles" help:"List the beacons of every profile, with a profile column"`
+}
 
 func (c *ListCmd) Run(cli *CLI) error {
-	b, err := cli.newBeacon()
+	err := c.list(cli)
+	var exitErr *ExitError
+	if c.ExitCode && err != nil && !errors.As(err, &exitErr) {
+		return &ExitError{Code: exitTrouble, Err: err}
+	}
+	return err
+}
+
+func (c *ListCmd) list(cli *CLI) error {
+	var filter *query.Filter
+	if c.Filter != "" {
+		var err error
+		if filter, err = query.ParseFilter(c.Filter); err != nil {
+			return err
+		}
+	}
+	keys, err := query.ParseSort(c.Sort)
 	if err != nil {
 		return err
 	}
-	return b.List()
+	selector, err := beacon.ParseSelector(c.Selector)
+	if err != nil {
+		return err
+	}
+
+	// Filters and sort keys may refer to context fields, so the context is
+	// loaded for them even if it is not shown.
+	withContext := c.WithContext || filter != nil || len(keys) > 0
+	var records []beacon.Record
+	if c.AllProfiles {
+		records, err = c.allProfiles(cli, withContext)
+	} else {
+		var b *beacon.Beacon
+		if b, err = cli.newBeacon(); err != nil {
+			return err
+		}
+		records, err = c.records(b, withContext)
+	}
+	if err != nil {
+		return err
+	}
+
+	records = slices.DeleteFunc(records, func(r beacon.Record) bool { return !selector.Matches(r.Labels) })
+	now := time.Now()
+	if records, err = query.Select(records, filter, keys, now); err != nil {
+		return err
+	}
+	if c.Limit > 0 && len(records) > c.Limit {
+		records = records[:c.Limit]
+	}
+	if !c.WithContext {
+		for i := range records {
+			records[i].Context, records[i].Location = nil, ""
+		}
+	}
+
+	format := cmp.Or(c.Format, cli.config.List.Format, "table")
+	err = render.Records(cli.out, records, render.Options{
+		Format:      render.Format(format),
+		Template:    c.Template,
+		WithContext: c.WithContext,
+		WithProfile: c.AllProfiles,
+		Color:       colorEnabled(cli.out),
+		Now:         now,
+	})
+	if err != nil {
+		return err
+	}
+	if c.ExitCode && len(records) > 0 {
+		return &ExitError{Code: exitMatch}
+	}
+	return nil
+}
+
+// records returns the beacons of b, reaping those of exited agents first
+// with --reap-dead.
+func (c *ListCmd) records(b *beacon.Beacon, withContext bool) ([]beacon.Record, error) {
+	if c.ReapDead {
+		if _, err := b.GC(beacon.GCOptions{Dead: true}); err != nil {
+			return nil, err
+		}
+	}
+	if withContext {
+		return b.ListWithContext()
+	}
+	states, err := b.List()
+	if err != nil {
+		return nil, err
+	}
+	return beacon.Records(states), nil
+}
+
+// allProfiles returns the beacons of every profile in the base directory,
+// grouped by profile.
+func (c *ListCmd) allProfiles(cli *CLI, withContext bool) ([]beacon.Record, error) {
+	if err := cli.initDefaults(); err != nil {
+		return nil, err
+	}
+	loc, err := cli.location()
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := loc.Profiles()
+	if err != nil {
+		return nil, err
+	}
+	var all []beacon.Record
+	for _, profile := range profiles {
+		b, err := cli.newBeaconAt(storage.Location{BaseDir: loc.BaseDir, Profile: profile})
+		if err != nil {
+			return nil, err
+		}
+		records, err := c.records(b, withContext)
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", profile, err)
+		}
+		for i := range records {
+			records[i].Profile = profile
+		}
+		all = append(all, records...)
+	}
+	return all, nil
+}
+
+// colorEnabled reports whether ANSI colors should be written to w.
+// Colors are only used for terminals and are disabled by NO_COLOR (https://no-color.org).
+func colorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
 
 type ContextCmd struct {
@@ -100,14 +347,30 @@ func (c *ContextCmd) Run(cli *CLI) error {
 
 type CLI struct {
 	Version kong.VersionFlag `help:"Show version"`
+	BaseDir string           `name:"base-dir" type:"path" env:"BEACON_DIR" help:"Directory holding the beacons (default: storage.base_dir, then $XDG_CACHE_HOME/beacon)"`
+	Profile string           `name:"profile" env:"BEACON_PROFILE" help:"Separate namespace of beacons, such as work (default: storage.profile)"`
 	Emit    EmitCmd          `cmd:"" help:"Emit a beacon signal"`
 	Silence SilenceCmd       `cmd:"" help:"Silence the beacon"`
 	List    ListCmd          `cmd:"" help:"List all active beacons"`
 	Context ContextCmd       `cmd:"" help:"Display context for a session"`
+	Watch   WatchCmd         `cmd:"" help:"Stream beacon changes until interrupted"`
+	GC      GCCmd            `cmd:"" name:"gc" help:"Remove expired and stale beacons"`
+	Jump    JumpCmd          `cmd:"" help:"Switch to the terminal location of a beacon"`
+	Pick    PickCmd          `cmd:"" help:"Interactively pick a beacon to jump to or silence"`
+	Status  StatusCmd        `cmd:"" help:"Summarize beacons waiting for attention"`
+	Init    InitCmd          `cmd:"" help:"Print shell or tmux integration snippets"`
+	Config  ConfigCmd        `cmd:"" help:"Read, change or check the config file"`
+	Hook    HookCmd          `cmd:"" help:"Translate the hook payloads of coding agents into beacons"`
 
+	NotifyListen NotifyListenCmd `cmd:"" name:"notify-listen" hidden:"" help:"Wait for the focus action of a desktop notification"`
+
+	config       *config.Config
 	store        beacon.Store
 	contextStore context.ContextStore
+	executor     context.CommandExecutor
+	in           io.Reader
 	out          io.Writer
+	errOut       io.Writer
 }
 
 func NewCLI() *CLI {
@@ -115,31 +378,174 @@ func NewCLI() *CLI {
 }
 
 func (c *CLI) initDefaults() error {
+	if c.config == nil {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		c.config = cfg
+	}
 	if c.contextStore == nil {
-		contextStore, err := context.NewFileContextStore()
+		loc, err := c.location()
 		if err != nil {
 			return err
 		}
-		c.contextStore = contextStore
+		if c.contextStore, err = context.NewFileContextStoreAt(loc); err != nil {
+			return err
+		}
+	}
+	if c.executor == nil {
+		c.executor = &context.DefaultExecutor{}
+	}
+	c.initStreams()
+	return nil
+}
+
+// initStreams defaults the standard streams, for commands that need
+// neither the config nor the stores.
+func (c *CLI) initStreams() {
+	if c.in == nil {
+		c.in = os.Stdin
 	}
 	if c.out == nil {
 		c.out = os.Stdout
 	}
-	return nil
+	if c.errOut == nil {
+		c.errOut = os.Stderr
+	}
+}
+
+// location returns where beacons are stored. --base-dir and --profile, or
+// their environment variables, take precedence over the config file.
+func (c *CLI) location() (storage.Location, error) {
+	loc := storage.Location{
+		BaseDir: c.BaseDir,
+		Profile: cmp.Or(c.Profile, c.config.Storage.Profile),
+	}
+	if loc.BaseDir != "" {
+		return loc, nil
+	}
+	dir := c.config.Storage.BaseDir
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return storage.Location{}, err
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+	loc.BaseDir = dir
+	return loc, nil
 }
 
+// resolveID returns id, or if it is empty the value of BEACON_ID or of the
+// environment variable named by id.env in the config file.
+func (c *CLI) resolveID(id string) (string, error) {
+	if id != "" {
+		return id, nil
+	}
+	if id := os.Getenv("BEACON_ID"); id != "" {
+		return id, nil
+	}
+	if name := c.config.ID.Env; name != "" {
+		if id := os.Getenv(name); id != "" {
+			return id, nil
+		}
+		return "", fmt.Errorf("missing --id: neither BEACON_ID nor %s is set", name)
+	}
+	return "", errors.New("missing --id: pass it or set BEACON_ID")
+}
+
+// newBeacon returns a Beacon for the selected profile.
 func (c *CLI) newBeacon() (*beacon.Beacon, error) {
+	if err := c.initDefaults(); err != nil {
+		return nil, err
+	}
+	loc, err := c.location()
+	if err != nil {
+		return nil, err
+	}
 	if c.store == nil {
-		store, err := beacon.NewFileStore()
-		if err != nil {
+		if c.store, err = beacon.NewFileStoreAt(loc); err != nil {
 			return nil, err
 		}
-		c.store = store
 	}
+	return c.assemble(c.store, c.contextStore, loc)
+}
+
+// newBeaconAt returns a Beacon for the files at loc, such as another profile.
+func (c *CLI) newBeaconAt(loc storage.Location) (*beacon.Beacon, error) {
 	if err := c.initDefaults(); err != nil {
 		return nil, err
 	}
-	return beacon.NewWithContextStore(c.store, c.contextStore, c.out), nil
+	store, err := beacon.NewFileStoreAt(loc)
+	if err != nil {
+		return nil, err
+	}
+	contextStore, err := context.NewFileContextStoreAt(loc)
+	if err != nil {
+		return nil, err
+	}
+	return c.assemble(store, contextStore, loc)
+}
+
+// assemble creates a Beacon on the stores with the indicators, notifiers
+// and hooks enabled in the config file.
+func (c *CLI) assemble(store beacon.Store, contextStore context.ContextStore, loc storage.Location) (*beacon.Beacon, error) {
+	b := beacon.NewWithContextStore(store, contextStore)
+	if c.config.Tmux.Indicator {
+		b.EnableIndicators(c.executor, context.IndicatorOptions{
+			WindowStatusStyle: c.config.Tmux.WindowStatusStyle,
+			DisplayMessage:    c.config.Tmux.DisplayMessage,
+		})
+	}
+	if c.config.Notify.Desktop {
+		dir, err := loc.Dir()
+		if err != nil {
+			return nil, err
+		}
+		b.AddNotifier(notify.NewDesktop(filepath.Join(dir, ".notify"), c.executor, listenerSpawner(loc)))
+	}
+	webhooks, err := newWebhooks(c.config)
+	if err != nil {
+		return nil, err
+	}
+	if len(webhooks) > 0 {
+		b.AddNotifier(notify.Webhooks(webhooks))
+	}
+	if hooks := c.config.Hooks; hooks != (config.HooksConfig{}) {
+		b.SetHooks(beacon.Hooks{
+			OnEmit:    hooks.OnEmit,
+			OnUpdate:  hooks.OnUpdate,
+			OnSilence: hooks.OnSilence,
+			Timeout:   hooks.Timeout,
+		})
+	}
+	b.OnNotifyError(func(err error) {
+		fmt.Fprintf(c.errOut, "Warning: %v\n", err)
+	})
+	return b, nil
+}
+
+// newWebhooks creates the webhooks configured in cfg, in name order.
+func newWebhooks(cfg *config.Config) ([]*notify.Webhook, error) {
+	var webhooks []*notify.Webhook
+	for _, name := range slices.Sorted(maps.Keys(cfg.Webhook)) {
+		webhook := cfg.Webhook[name]
+		notifier, err := notify.NewWebhook(notify.WebhookOptions{
+			Name:     name,
+			URL:      webhook.URL,
+			Format:   webhook.Format,
+			Template: webhook.Template,
+			Headers:  webhook.Headers,
+			Timeout:  webhook.Timeout,
+			Retries:  webhook.Retries,
+		})
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, notifier)
+	}
+	return webhooks, nil
 }
 
 func (c *CLI) getContextStore() (context.ContextStore, error) {
@@ -150,9 +556,12 @@ func (c *CLI) getContextStore() (context.ContextStore, error) {
 }
 
 func (c *CLI) getContext(contextType string) (context.Context, error) {
+	if err := c.initDefaults(); err != nil {
+		return nil, err
+	}
 	switch contextType {
 	case "tmux":
-		provider := context.NewTmuxProvider()
+		provider := context.NewTmuxProviderWithExecutor(c.executor)
 		return provider.GetContext()
 	default:
 		return nil, errors.New("unknown context type: " + contextType)
@@ -174,6 +583,10 @@ func (c *CLI) Execute(args []string) error {
 	}
 	ctx, err := parser.Parse(args)
 	if err != nil {
+		// Usage errors are rejected before ListCmd.Run can tell them from a match.
+		if exitCodeRequested(args) {
+			return &ExitError{Code: exitTrouble, Err: err}
+		}
 		return err
 	}
 	return ctx.Run(c)
@@ -2,10 +2,19 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/config"
 	"github.com/monochromegane/beacon/internal/context"
 )
 
@@ -17,16 +26,24 @@ func TestNewCLI(t *testing.T) {
 }
 
 type mockStore struct {
-	states map[string]string
+	states map[string]beacon.State
 }
 
 func newMockStore() *mockStore {
-	return &mockStore{states: make(map[string]string)}
+	return &mockStore{states: make(map[string]beacon.State)}
 }
 
-func (m *mockStore) Write(id string, message string) error {
-	m.states[id] = message
-	return nil
+func (m *mockStore) Update(id string, fn func(state *beacon.State) error) (beacon.State, error) {
+	state, ok := m.states[id]
+	if !ok {
+		state = beacon.State{ID: id}
+	}
+	if err := fn(&state); err != nil {
+		return beacon.State{}, err
+	}
+	state.EmitCount++
+	m.states[id] = state
+	return state, nil
 }
 
 func (m *mockStore) Delete(id string) error {
@@ -36,8 +53,8 @@ func (m *mockStore) Delete(id string) error {
 
 func (m *mockStore) List() ([]beacon.State, error) {
 	var states []beacon.State
-	for id, msg := range m.states {
-		states = append(states, beacon.State{ID: id, Message: msg})
+	for _, state := range m.states {
+		states = append(states, state)
 	}
 	return states, nil
 }
@@ -80,14 +97,121 @@ func TestCLI_Emit(t *testing.T) {
 		t.Fatalf("Execute() error = %v", err)
 	}
 
-	if store.states["test123"] != "test message" {
-		t.Errorf("Emit message = %q, want %q", store.states["test123"], "test message")
+	if store.states["test123"].Message != "test message" {
+		t.Errorf("Emit message = %q, want %q", store.states["test123"].Message, "test message")
+	}
+	if store.states["test123"].Status != beacon.StatusWaiting {
+		t.Errorf("Emit status = %q, want %q", store.states["test123"].Status, beacon.StatusWaiting)
+	}
+}
+
+func TestCLI_Emit_Status(t *testing.T) {
+	store := newMockStore()
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+
+	err := cli.Execute([]string{"emit", "--id", "test123", "--status", "running", "working"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if store.states["test123"].Status != beacon.StatusRunning {
+		t.Errorf("Emit status = %q, want %q", store.states["test123"].Status, beacon.StatusRunning)
+	}
+}
+
+func TestCLI_Emit_TTL(t *testing.T) {
+	store := newMockStore()
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+
+	before := time.Now()
+	err := cli.Execute([]string{"emit", "--id", "test123", "--ttl", "30m", "test message"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	expiresAt := store.states["test123"].ExpiresAt
+	if expiresAt.Before(before.Add(30*time.Minute)) || expiresAt.After(time.Now().Add(30*time.Minute)) {
+		t.Errorf("Emit expires_at = %v, want about 30m from now", expiresAt)
+	}
+
+	err = cli.Execute([]string{"emit", "--id", "negative", "--ttl=-5m", "test message"})
+	if err == nil || !strings.Contains(err.Error(), "--ttl must not be negative") {
+		t.Errorf("Execute() with a negative --ttl error = %v, want a usage error", err)
+	}
+	if _, ok := store.states["negative"]; ok {
+		t.Error("Execute() with a negative --ttl emitted the beacon")
+	}
+}
+
+func TestCLI_Emit_ExpiresAt(t *testing.T) {
+	store := newMockStore()
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+
+	err := cli.Execute([]string{"emit", "--id", "test123", "--expires-at", "2026-01-01T10:30:00Z", "test message"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+	if !store.states["test123"].ExpiresAt.Equal(want) {
+		t.Errorf("Emit expires_at = %v, want %v", store.states["test123"].ExpiresAt, want)
+	}
+
+	err = cli.Execute([]string{"emit", "--id", "test123", "--ttl", "1m", "--expires-at", "2026-01-01T10:30:00Z", "test message"})
+	if err == nil {
+		t.Error("Execute() expected error for --ttl with --expires-at, got nil")
+	}
+}
+
+func TestCLI_Emit_PID(t *testing.T) {
+	store := newMockStore()
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+
+	err := cli.Execute([]string{"emit", "--id", "test123", "test message"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if store.states["test123"].Owner.PID != os.Getppid() {
+		t.Errorf("Emit owner pid = %d, want parent pid %d", store.states["test123"].Owner.PID, os.Getppid())
+	}
+
+	err = cli.Execute([]string{"emit", "--id", "test123", "--pid", "4242", "test message"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if store.states["test123"].Owner.PID != 4242 {
+		t.Errorf("Emit owner pid = %d, want 4242", store.states["test123"].Owner.PID)
+	}
+}
+
+func TestCLI_Emit_InvalidTransition(t *testing.T) {
+	store := newMockStore()
+	store.states["test123"] = beacon.State{ID: "test123", Status: beacon.StatusDone, EmitCount: 1}
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+
+	err := cli.Execute([]string{"emit", "--id", "test123", "--status", "failed", "oops"})
+	if !errors.Is(err, beacon.ErrInvalidTransition) {
+		t.Fatalf("Execute() error = %v, want ErrInvalidTransition", err)
+	}
+
+	err = cli.Execute([]string{"emit", "--id", "test123", "--status", "failed", "--force", "oops"})
+	if err != nil {
+		t.Fatalf("Execute() with --force error = %v", err)
 	}
 }
 
 func TestCLI_Silence(t *testing.T) {
 	store := newMockStore()
-	store.states["test123"] = "existing message"
+	store.states["test123"] = beacon.State{ID: "test123", Message: "existing message"}
 	contextStore := newMockContextStore()
 	cli := NewCLI()
 	cli.store = store
@@ -103,9 +227,128 @@ func TestCLI_Silence(t *testing.T) {
 	}
 }
 
+func TestCLI_Emit_Labels(t *testing.T) {
+	store := newMockStore()
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+
+	err := cli.Execute([]string{"emit", "--id", "test123", "--label", "project=web", "-l", "agent=claude", "message"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := store.states["test123"].Labels.String(); got != "agent=claude,project=web" {
+		t.Errorf("Labels = %s, want agent=claude,project=web", got)
+	}
+
+	if err := cli.Execute([]string{"emit", "--id", "test123", "--label", "project", "message"}); err == nil {
+		t.Error("Execute() expected error for label without value, got nil")
+	}
+}
+
+func TestCLI_Emit_FromJSON(t *testing.T) {
+	t.Setenv("BEACON_ID", "")
+	store := newMockStore()
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+	mapping := []string{"emit", "--from-json", "--id-path", ".session.id", "--message-path", ".msg", "--silence-if", ".done", "-s", "running"}
+
+	cli.in = strings.NewReader(`{"session":{"id":"build-42"},"msg":"Compiling","done":false}`)
+	if err := cli.Execute(mapping); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if state := store.states["build-42"]; state.Message != "Compiling" || state.Status != beacon.StatusRunning {
+		t.Errorf("state = %+v, want running with the message from .msg", state)
+	}
+
+	cli.in = strings.NewReader(`{"repo":"web","ref":"main"}`)
+	if err := cli.Execute([]string{"emit", "--from-json", "--id", "deploy", "--message-template", "Deploy {{.repo}}@{{.ref}}?"}); err != nil {
+		t.Fatalf("Execute(--message-template) error = %v", err)
+	}
+	if got := store.states["deploy"].Message; got != "Deploy web@main?" {
+		t.Errorf("message = %q, want %q", got, "Deploy web@main?")
+	}
+
+	cli.in = strings.NewReader(`{"session":{"id":"build-42"},"done":true}`)
+	if err := cli.Execute(mapping); err != nil {
+		t.Fatalf("Execute(done) error = %v", err)
+	}
+	if _, ok := store.states["build-42"]; ok {
+		t.Error("--silence-if did not silence the beacon")
+	}
+
+	cli.in = strings.NewReader(`{"session":{},"msg":"Compiling"}`)
+	err := cli.Execute(mapping)
+	if err == nil || err.Error() != `.session.id did not resolve: .session has no key "id"` {
+		t.Errorf("Execute() with a missing ID error = %v", err)
+	}
+
+	if err := cli.Execute([]string{"emit", "--id", "build-42", "--message-path", ".msg"}); err == nil {
+		t.Error("Execute() expected error for --message-path without --from-json, got nil")
+	}
+	if err := cli.Execute([]string{"emit", "--id", "build-42"}); err == nil {
+		t.Error("Execute() expected error for a missing message, got nil")
+	}
+}
+
+func TestCLI_Silence_Selector(t *testing.T) {
+	store := newMockStore()
+	store.states["a"] = beacon.State{ID: "a", Labels: beacon.Labels{"project": "web", "kind": "approval"}}
+	store.states["b"] = beacon.State{ID: "b", Labels: beacon.Labels{"project": "web"}}
+	store.states["c"] = beacon.State{ID: "c", Labels: beacon.Labels{"project": "api"}}
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+
+	if err := cli.Execute([]string{"silence", "-l", "project=web,kind!=approval"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if _, ok := store.states["b"]; ok {
+		t.Error("Silence did not delete the selected beacon")
+	}
+	if len(store.states) != 2 {
+		t.Errorf("remaining states = %d, want 2", len(store.states))
+	}
+
+	if err := cli.Execute([]string{"silence"}); err == nil {
+		t.Error("Execute() expected error without --id or --selector, got nil")
+	}
+	if err := cli.Execute([]string{"silence", "--id", "a", "-l", "project=web"}); err == nil {
+		t.Error("Execute() expected error with both --id and --selector, got nil")
+	}
+}
+
+func TestCLI_List_Selector(t *testing.T) {
+	store := newMockStore()
+	store.states["a"] = beacon.State{ID: "a", Status: beacon.StatusWaiting, Labels: beacon.Labels{"project": "web", "agent": "claude"}}
+	store.states["b"] = beacon.State{ID: "b", Status: beacon.StatusWaiting, Labels: beacon.Labels{"project": "api"}}
+	var buf bytes.Buffer
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+	cli.out = &buf
+
+	if err := cli.Execute([]string{"list", "-l", "project=web"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	expected := "ID  STATUS             AGE  LABELS                    MESSAGE\na   waiting-for-input  -    agent=claude,project=web\n"
+	if buf.String() != expected {
+		t.Errorf("output = %q, want %q", buf.String(), expected)
+	}
+
+	buf.Reset()
+	if err := cli.Execute([]string{"list", "-t", "{{.ID}} {{.Labels.project}}", "--filter", "labels.agent != claude"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if buf.String() != "b api\n" {
+		t.Errorf("output = %q, want %q", buf.String(), "b api\n")
+	}
+}
+
 func TestCLI_List(t *testing.T) {
 	store := newMockStore()
-	store.states["test123"] = "message 1"
+	store.states["test123"] = beacon.State{ID: "test123", Message: "message 1", Status: beacon.StatusWaiting}
 	contextStore := newMockContextStore()
 	var buf bytes.Buffer
 	cli := NewCLI()
@@ -118,12 +361,255 @@ func TestCLI_List(t *testing.T) {
 		t.Fatalf("Execute() error = %v", err)
 	}
 
-	expected := "test123\tmessage 1\n"
+	expected := "ID       STATUS             AGE  MESSAGE\ntest123  waiting-for-input  -    message 1\n"
 	if buf.String() != expected {
 		t.Errorf("List output = %q, want %q", buf.String(), expected)
 	}
 }
 
+func TestCLI_List_Formats(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "tsv",
+			args: []string{"list", "--format", "tsv"},
+			want: "test123\twaiting-for-input\tmessage 1\n",
+		},
+		{
+			name: "ndjson",
+			args: []string{"list", "-f", "ndjson"},
+			want: `{"version":1,"id":"test123","message":"message 1","status":"waiting-for-input","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z","emit_count":1,"emitter_pid":0,"hostname":""}` + "\n",
+		},
+		{
+			name: "template",
+			args: []string{"list", "--format", "json", "--template", "{{.ID}}={{.Status}}"},
+			want: "test123=waiting-for-input\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockStore()
+			store.states["test123"] = beacon.State{Version: 1, ID: "test123", Message: "message 1", Status: beacon.StatusWaiting, EmitCount: 1}
+			var buf bytes.Buffer
+			cli := NewCLI()
+			cli.store = store
+			cli.contextStore = newMockContextStore()
+			cli.out = &buf
+
+			if err := cli.Execute(tt.args); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_List_JSON(t *testing.T) {
+	store := newMockStore()
+	store.states["b"] = beacon.State{ID: "b", Message: "second", Status: beacon.StatusRunning}
+	store.states["a"] = beacon.State{ID: "a", Message: "first", Status: beacon.StatusWaiting}
+	var buf bytes.Buffer
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+	cli.out = &buf
+
+	if err := cli.Execute([]string{"list", "--format", "json"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	var states []beacon.State
+	if err := json.Unmarshal(buf.Bytes(), &states); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+	}
+	if len(states) != 2 || states[0].ID != "a" || states[1].ID != "b" {
+		t.Errorf("states = %+v, want a then b", states)
+	}
+}
+
+func TestCLI_List_WithContext(t *testing.T) {
+	store := newMockStore()
+	store.states["a"] = beacon.State{ID: "a", Message: "first", Status: beacon.StatusWaiting}
+	store.states["b"] = beacon.State{ID: "b", Message: "second", Status: beacon.StatusRunning}
+	contextStore := newMockContextStore()
+	contextStore.contexts["a"] = &context.TmuxContext{SessionName: "main", WindowIndex: 1, PaneIndex: 0, PaneID: "%1"}
+	var buf bytes.Buffer
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = contextStore
+	cli.out = &buf
+
+	err := cli.Execute([]string{"list", "--with-context", "--template", "{{.ID}} {{.Context.tmux.session_name}} {{.Location}}"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	expected := "a main main:1.0\nb  \n"
+	if buf.String() != expected {
+		t.Errorf("output = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestCLI_List_FilterSortLimit(t *testing.T) {
+	now := time.Now()
+	store := newMockStore()
+	store.states["a"] = beacon.State{ID: "a", Status: beacon.StatusWaiting, UpdatedAt: now.Add(-10 * time.Minute)}
+	store.states["b"] = beacon.State{ID: "b", Status: beacon.StatusWaiting, UpdatedAt: now.Add(-time.Hour)}
+	store.states["c"] = beacon.State{ID: "c", Status: beacon.StatusRunning, UpdatedAt: now.Add(-time.Hour)}
+	store.states["d"] = beacon.State{ID: "d", Status: beacon.StatusWaiting, UpdatedAt: now.Add(-time.Hour)}
+	contextStore := newMockContextStore()
+	contextStore.contexts["a"] = &context.TmuxContext{SessionName: "api", PaneID: "%1"}
+	contextStore.contexts["b"] = &context.TmuxContext{SessionName: "api", PaneID: "%2"}
+	contextStore.contexts["c"] = &context.TmuxContext{SessionName: "api", PaneID: "%3"}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "filter on context",
+			args: []string{"--filter", "context.tmux.session_name = api and status = waiting-for-input and age > 5m"},
+			want: "a\nb\n",
+		},
+		{
+			name: "sort",
+			args: []string{"--sort=-age,-id"},
+			want: "d\nc\nb\na\n",
+		},
+		{
+			name: "limit",
+			args: []string{"--filter", "age > 30m", "--sort", "id", "--limit", "2"},
+			want: "b\nc\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cli := NewCLI()
+			cli.store = store
+			cli.contextStore = contextStore
+			cli.out = &buf
+
+			args := append([]string{"list", "-t", "{{.ID}}"}, tt.args...)
+			if err := cli.Execute(args); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_List_FilterHidesContext(t *testing.T) {
+	store := newMockStore()
+	store.states["a"] = beacon.State{ID: "a", Status: beacon.StatusWaiting}
+	contextStore := newMockContextStore()
+	contextStore.contexts["a"] = &context.TmuxContext{SessionName: "api", PaneID: "%1"}
+	var buf bytes.Buffer
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = contextStore
+	cli.out = &buf
+
+	if err := cli.Execute([]string{"list", "-f", "ndjson", "--filter", "context.tmux.session_name = api"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if buf.Len() == 0 || bytes.Contains(buf.Bytes(), []byte(`"context"`)) {
+		t.Errorf("output = %q, want a record without context", buf.String())
+	}
+}
+
+func TestCLI_List_InvalidFilter(t *testing.T) {
+	cli := NewCLI()
+	cli.store = newMockStore()
+	cli.contextStore = newMockContextStore()
+	cli.out = &bytes.Buffer{}
+
+	if err := cli.Execute([]string{"list", "--filter", "status ="}); err == nil {
+		t.Error("Execute() expected error for invalid filter, got nil")
+	}
+}
+
+func TestCLI_List_ExitCode(t *testing.T) {
+	store := newMockStore()
+	store.states["a"] = beacon.State{ID: "a", Status: beacon.StatusRunning}
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+	cli.out = &bytes.Buffer{}
+
+	err := cli.Execute([]string{"list", "--exit-code", "--filter", "status = waiting-for-input"})
+	if err != nil {
+		t.Fatalf("Execute() without matches error = %v, want nil", err)
+	}
+
+	err = cli.Execute([]string{"list", "--exit-code", "--filter", "status = running"})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 || exitErr.Err != nil {
+		t.Errorf("Execute() with matches error = %v, want exit status 1", err)
+	}
+
+	// Errors exit with another status than matches.
+	err = cli.Execute([]string{"list", "--exit-code", "--filter", "age >"})
+	if !errors.As(err, &exitErr) || exitErr.Code != 2 || exitErr.Err == nil {
+		t.Errorf("Execute() with a bad filter error = %#v, want exit status 2", err)
+	}
+	err = cli.Execute([]string{"list", "--filter", "age >"})
+	if errors.As(err, &exitErr) {
+		t.Errorf("Execute() with a bad filter without --exit-code error = %#v, want a plain error", err)
+	}
+
+	// So do arguments rejected before the command runs.
+	for _, args := range [][]string{
+		{"list", "--exit-code", "--format", "bogus"},
+		{"list", "--format=bogus", "--exit-code=true"},
+		{"list", "--exit-code", "--limit", "many"},
+	} {
+		err = cli.Execute(args)
+		if !errors.As(err, &exitErr) || exitErr.Code != 2 || exitErr.Err == nil {
+			t.Errorf("Execute(%q) error = %#v, want exit status 2", args, err)
+		}
+	}
+	err = cli.Execute([]string{"list", "--format", "bogus"})
+	if errors.As(err, &exitErr) {
+		t.Errorf("Execute() with a bad format without --exit-code error = %#v, want a plain error", err)
+	}
+}
+
+func TestCLI_List_UnknownFormat(t *testing.T) {
+	cli := NewCLI()
+	cli.store = newMockStore()
+	cli.contextStore = newMockContextStore()
+	cli.out = &bytes.Buffer{}
+
+	if err := cli.Execute([]string{"list", "--format", "yaml"}); err == nil {
+		t.Error("Execute() expected error for unknown format, got nil")
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	if colorEnabled(&bytes.Buffer{}) {
+		t.Error("colorEnabled(buffer) = true, want false")
+	}
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if colorEnabled(f) {
+		t.Error("colorEnabled(regular file) = true, want false")
+	}
+}
+
 func TestCLI_Emit_WithContext_NotInTmux(t *testing.T) {
 	originalTmux := os.Getenv("TMUX")
 	os.Unsetenv("TMUX")
@@ -232,3 +718,104 @@ func TestCLI_Context_InvalidTemplate(t *testing.T) {
 		t.Error("Execute() expected error for invalid template, got nil")
 	}
 }
+
+func TestCLI_Indicators(t *testing.T) {
+	store := newMockStore()
+	contextStore := newMockContextStore()
+	contextStore.contexts["test123"] = &context.TmuxContext{SessionName: "main", WindowIndex: 1, PaneIndex: 0, PaneID: "%4"}
+	executor := &mockExecutor{}
+	cli := NewCLI()
+	cli.config = &config.Config{Tmux: config.TmuxConfig{Indicator: true}}
+	cli.store = store
+	cli.contextStore = contextStore
+	cli.executor = executor
+
+	if err := cli.Execute([]string{"emit", "--id", "test123", "-s", "blocked", "message"}); err != nil {
+		t.Fatalf("Execute(emit) error = %v", err)
+	}
+	if err := cli.Execute([]string{"silence", "--id", "test123"}); err != nil {
+		t.Fatalf("Execute(silence) error = %v", err)
+	}
+
+	want := "tmux set-option -p -t %4 @beacon_status blocked|" +
+		"tmux set-option -w -t %4 @beacon_status blocked|" +
+		"tmux set-option -p -u -t %4 @beacon_status|" +
+		"tmux list-panes -t %4 -F #{pane_id}|" +
+		"tmux set-option -w -u -t %4 @beacon_status"
+	if got := strings.Join(executor.calls, "|"); got != want {
+		t.Errorf("executed %q, want %q", got, want)
+	}
+}
+
+func TestCLI_Indicators_Disabled(t *testing.T) {
+	contextStore := newMockContextStore()
+	contextStore.contexts["test123"] = &context.TmuxContext{SessionName: "main", PaneID: "%4"}
+	executor := &mockExecutor{}
+	cli := NewCLI()
+	cli.store = newMockStore()
+	cli.contextStore = contextStore
+	cli.executor = executor
+
+	if err := cli.Execute([]string{"emit", "--id", "test123", "message"}); err != nil {
+		t.Fatalf("Execute(emit) error = %v", err)
+	}
+	if len(executor.calls) != 0 {
+		t.Errorf("executed %v without indicators enabled", executor.calls)
+	}
+}
+
+func TestCLI_Webhooks(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies[r.URL.Path] = append(bodies[r.URL.Path], string(body))
+		mu.Unlock()
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var errOut bytes.Buffer
+	cli := NewCLI()
+	cli.config = &config.Config{Webhook: map[string]config.WebhookConfig{
+		"chat":   {URL: server.URL + "/chat", Format: "slack"},
+		"broken": {URL: server.URL + "/broken"},
+	}}
+	cli.store = newMockStore()
+	cli.contextStore = newMockContextStore()
+	cli.errOut = &errOut
+
+	if err := cli.Execute([]string{"emit", "--id", "test123", "-s", "blocked", "need help"}); err != nil {
+		t.Fatalf("Execute(emit) error = %v, want nil despite the broken webhook", err)
+	}
+	if err := cli.Execute([]string{"silence", "--id", "test123"}); err != nil {
+		t.Fatalf("Execute(silence) error = %v", err)
+	}
+
+	// Webhooks are called concurrently, so only the order per webhook is fixed.
+	chat := bodies["/chat"]
+	if len(chat) != 2 || chat[0] != `{"text":"test123 is blocked: need help"}` || chat[1] != `{"text":"test123 was silenced"}` {
+		t.Errorf("bodies of chat = %q", chat)
+	}
+	if len(bodies["/broken"]) != 2 {
+		t.Errorf("bodies of broken = %q", bodies["/broken"])
+	}
+	if got := errOut.String(); strings.Count(got, "Warning: webhook broken: 404 Not Found") != 2 {
+		t.Errorf("stderr = %q, want a warning per event", got)
+	}
+}
+
+func TestCLI_WebhookConfigError(t *testing.T) {
+	cli := NewCLI()
+	cli.config = &config.Config{Webhook: map[string]config.WebhookConfig{"chat": {Format: "slack"}}}
+	cli.store = newMockStore()
+	cli.contextStore = newMockContextStore()
+
+	err := cli.Execute([]string{"emit", "--id", "test123", "message"})
+	if err == nil || err.Error() != "webhook chat: url is required" {
+		t.Errorf("Execute(emit) error = %v, want the missing url", err)
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/monochromegane/beacon/internal/config"
+)
+
+type ConfigCmd struct {
+	Get      ConfigGetCmd      `cmd:"" help:"Print the value of a setting, such as emit.context"`
+	Set      ConfigSetCmd      `cmd:"" help:"Change a setting in the config file"`
+	Validate ConfigValidateCmd `cmd:"" help:"Check the config file and report the first bad line"`
+}
+
+type ConfigGetCmd struct {
+	Key string `arg:"" help:"Dotted key of the setting (e.g. list.format, webhook.slack.url)"`
+}
+
+func (c *ConfigGetCmd) Run(cli *CLI) error {
+	if err := cli.initDefaults(); err != nil {
+		return err
+	}
+	value, err := cli.config.Get(c.Key)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(cli.out, value)
+	return err
+}
+
+type ConfigSetCmd struct {
+	Key   string `arg:"" help:"Dotted key of the setting (e.g. list.format, webhook.slack.url)"`
+	Value string `arg:"" help:"New value; durations are written like 5s"`
+}
+
+func (c *ConfigSetCmd) Run(cli *CLI) error {
+	path, err := config.ResolvePath()
+	if err != nil {
+		return err
+	}
+	return config.SetFile(path, c.Key, c.Value)
+}
+
+type ConfigValidateCmd struct{}
+
+func (c *ConfigValidateCmd) Run(cli *CLI) error {
+	path, err := config.ResolvePath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := newWebhooks(cfg); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	cli.config = cfg
+	if err := cli.initDefaults(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(cli.out, "%s: ok\n", path)
+	return err
+}
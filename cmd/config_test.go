@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/config"
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+func TestCLI_Config(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path := filepath.Join(dir, "beacon", "config.toml")
+
+	run := func(args ...string) (string, error) {
+		var buf bytes.Buffer
+		cli := NewCLI()
+		cli.contextStore = newMockContextStore()
+		cli.out = &buf
+		err := cli.Execute(args)
+		return buf.String(), err
+	}
+
+	if _, err := run("config", "set", "list.format", "json"); err != nil {
+		t.Fatalf("config set error = %v", err)
+	}
+	if _, err := run("config", "set", "webhook.chat.url", "https://example.com/hook"); err != nil {
+		t.Fatalf("config set error = %v", err)
+	}
+	if out, err := run("config", "get", "list.format"); err != nil || out != "json\n" {
+		t.Errorf("config get = %q, %v, want json", out, err)
+	}
+	if out, err := run("config", "get", "webhook.chat.retries"); err != nil || out != "2\n" {
+		t.Errorf("config get = %q, %v, want the default 2", out, err)
+	}
+	if out, err := run("config", "validate"); err != nil || out != path+": ok\n" {
+		t.Errorf("config validate = %q, %v", out, err)
+	}
+
+	if _, err := run("config", "set", "list.format", "yaml"); err == nil || !strings.Contains(err.Error(), "expected one of table, json, ndjson, tsv") {
+		t.Errorf("config set of a bad value error = %v", err)
+	}
+	if _, err := run("config", "get", "list.colour"); !errors.Is(err, config.ErrUnknownKey) {
+		t.Errorf("config get of an unknown key error = %v", err)
+	}
+}
+
+func TestCLI_Config_ValidateErrors(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path := filepath.Join(dir, "beacon", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"[emit]\n\ncontext = \"screen\"\n", path + `:3: emit.context: expected one of none, tmux, got "screen"`},
+		{"[webhook.chat]\nformat = \"slack\"\n", path + ": webhook chat: url is required"},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(path, []byte(tt.input), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cli := NewCLI()
+		cli.out = &bytes.Buffer{}
+		if err := cli.Execute([]string{"config", "validate"}); err == nil || err.Error() != tt.want {
+			t.Errorf("config validate of %q error = %v, want %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestCLI_List_FormatPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want string
+	}{
+		{name: "file", want: "test123\twaiting-for-input\tmessage 1\n"},
+		{name: "env over file", env: "ndjson", want: `{"version":0,"id":"test123"`},
+		{name: "flag over env", env: "ndjson", args: []string{"--format", "table"}, want: "ID       STATUS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BEACON_LIST_FORMAT", tt.env)
+			if tt.env == "" {
+				os.Unsetenv("BEACON_LIST_FORMAT")
+			}
+			store := newMockStore()
+			store.states["test123"] = beacon.State{ID: "test123", Message: "message 1", Status: beacon.StatusWaiting}
+			var buf bytes.Buffer
+			cli := NewCLI()
+			cli.config = &config.Config{List: config.ListConfig{Format: "tsv"}}
+			cli.store = store
+			cli.contextStore = newMockContextStore()
+			cli.out = &buf
+
+			if err := cli.Execute(append([]string{"list"}, tt.args...)); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !strings.HasPrefix(buf.String(), tt.want) {
+				t.Errorf("output = %q, want prefix %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCLI_Emit_ContextPrecedence(t *testing.T) {
+	// Outside tmux, recording a tmux context fails, which shows whether it was asked for.
+	t.Setenv("TMUX", "")
+	tests := []struct {
+		name    string
+		env     string
+		args    []string
+		wantErr error
+	}{
+		{name: "file", wantErr: context.ErrNotInTmux},
+		{name: "env over file", env: "none"},
+		{name: "flag over env", env: "none", args: []string{"--context", "tmux"}, wantErr: context.ErrNotInTmux},
+		{name: "flag over file", args: []string{"-c", "none"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BEACON_CONTEXT", tt.env)
+			if tt.env == "" {
+				os.Unsetenv("BEACON_CONTEXT")
+			}
+			store := newMockStore()
+			cli := NewCLI()
+			cli.config = &config.Config{Emit: config.EmitConfig{Context: "tmux"}}
+			cli.store = store
+			cli.contextStore = newMockContextStore()
+
+			err := cli.Execute(append([]string{"emit", "--id", "test123"}, append(tt.args, "message")...))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, emitted := store.states["test123"]; emitted != (tt.wantErr == nil) {
+				t.Errorf("emitted = %v", emitted)
+			}
+		})
+	}
+}
+
+func TestCLI_ResolveID(t *testing.T) {
+	t.Setenv("TMUX_PANE", "%7")
+	t.Setenv("BEACON_ID", "")
+	store := newMockStore()
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+
+	if err := cli.Execute([]string{"emit", "message"}); err == nil || err.Error() != "missing --id: pass it or set BEACON_ID" {
+		t.Errorf("Execute() without an ID error = %v", err)
+	}
+
+	cli.config = &config.Config{ID: config.IDConfig{Env: "TMUX_PANE"}}
+	if err := cli.Execute([]string{"emit", "from id.env"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	t.Setenv("BEACON_ID", "env-id")
+	if err := cli.Execute([]string{"emit", "from BEACON_ID"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if err := cli.Execute([]string{"emit", "--id", "flag-id", "from the flag"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	for id, message := range map[string]string{"%7": "from id.env", "env-id": "from BEACON_ID", "flag-id": "from the flag"} {
+		if got := store.states[id].Message; got != message {
+			t.Errorf("beacon %s message = %q, want %q", id, got, message)
+		}
+	}
+
+	if err := cli.Execute([]string{"silence"}); err != nil {
+		t.Fatalf("Execute(silence) error = %v", err)
+	}
+	if _, ok := store.states["env-id"]; ok {
+		t.Error("silence without --id kept the beacon named by BEACON_ID")
+	}
+
+	t.Setenv("BEACON_ID", "")
+	t.Setenv("TMUX_PANE", "")
+	if err := cli.Execute([]string{"silence"}); err == nil || err.Error() != "missing --id: neither BEACON_ID nor TMUX_PANE is set" {
+		t.Errorf("Execute(silence) without an ID error = %v", err)
+	}
+}
+
+func TestCLI_BaseDir(t *testing.T) {
+	dir := t.TempDir()
+	cli := NewCLI()
+	cli.config = &config.Config{Storage: config.StorageConfig{BaseDir: dir}}
+	cli.out = &bytes.Buffer{}
+
+	if err := cli.Execute([]string{"emit", "--id", "test123", "message"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "test123")); err != nil {
+		t.Errorf("beacon was not stored in storage.base_dir: %v", err)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip(err)
+	}
+	cli.config.Storage.BaseDir = "~/beacons"
+	if got, err := cli.location(); err != nil || got.BaseDir != filepath.Join(home, "beacons") {
+		t.Errorf("location() = %+v, %v, want it below the home directory", got, err)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd
+
+package cmd
+
+import "syscall"
+
+// detachedProcAttr starts a process in its own session, so it outlives the
+// terminal it was started from.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
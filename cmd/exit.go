@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// exitMatch is the status of list --exit-code when beacons are listed.
+	exitMatch = 1
+	// exitTrouble is the status of list --exit-code when it fails, so that
+	// scripts can tell an error from a match, as with grep.
+	exitTrouble = 2
+)
+
+// ExitError requests a non-zero exit status, such as list --exit-code
+// finding matching beacons. Only an ExitError wrapping Err is reported as
+// an error.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// exitCodeRequested reports whether args ask for list --exit-code, so that
+// arguments that do not parse still exit with exitTrouble.
+func exitCodeRequested(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if arg == "--exit-code" || strings.HasPrefix(arg, "--exit-code=") {
+			return true
+		}
+	}
+	return false
+}
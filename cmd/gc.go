@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+)
+
+type GCCmd struct {
+	OlderThan time.Duration `name:"older-than" help:"Also remove beacons not updated within this duration (e.g. 24h)"`
+	Dead      bool          `name:"dead" help:"Also remove beacons whose owner process has exited"`
+	Tmux      bool          `name:"tmux" help:"Also remove beacons whose tmux pane no longer exists"`
+	DryRun    bool          `name:"dry-run" short:"n" help:"Report what would be removed without deleting anything"`
+}
+
+func (c *GCCmd) Run(cli *CLI) error {
+	b, err := cli.newBeacon()
+	if err != nil {
+		return err
+	}
+
+	removals, err := b.GC(beacon.GCOptions{
+		OlderThan: c.OlderThan,
+		Dead:      c.Dead,
+		Tmux:      c.Tmux,
+		DryRun:    c.DryRun,
+	})
+	action := "removed"
+	if c.DryRun {
+		action = "would remove"
+	}
+	for _, removal := range removals {
+		fmt.Fprintf(cli.out, "%s\t%s\t%s\n", action, removal.ID, removal.Reason)
+	}
+	return err
+}
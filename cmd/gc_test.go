@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/process"
+)
+
+func TestCLI_GC(t *testing.T) {
+	store := newMockStore()
+	store.states["expired"] = beacon.State{ID: "expired", UpdatedAt: time.Now(), ExpiresAt: time.Now().Add(-time.Minute)}
+	store.states["stale"] = beacon.State{ID: "stale", UpdatedAt: time.Now().Add(-48 * time.Hour)}
+	store.states["fresh"] = beacon.State{ID: "fresh", UpdatedAt: time.Now()}
+	var buf bytes.Buffer
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+	cli.out = &buf
+
+	err := cli.Execute([]string{"gc", "--older-than", "24h"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	expected := "removed\texpired\texpired\nremoved\tstale\tnot updated for 24h0m0s\n"
+	if buf.String() != expected {
+		t.Errorf("GC output = %q, want %q", buf.String(), expected)
+	}
+	if len(store.states) != 1 {
+		t.Errorf("GC remaining states = %d, want 1", len(store.states))
+	}
+}
+
+func TestCLI_GC_DryRun(t *testing.T) {
+	store := newMockStore()
+	store.states["expired"] = beacon.State{ID: "expired", UpdatedAt: time.Now(), ExpiresAt: time.Now().Add(-time.Minute)}
+	var buf bytes.Buffer
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+	cli.out = &buf
+
+	err := cli.Execute([]string{"gc", "--dry-run"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	expected := "would remove\texpired\texpired\n"
+	if buf.String() != expected {
+		t.Errorf("GC output = %q, want %q", buf.String(), expected)
+	}
+	if len(store.states) != 1 {
+		t.Error("GC --dry-run removed a state")
+	}
+}
+
+func TestCLI_GC_Dead(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("proc filesystem not available")
+	}
+	store := newMockStore()
+	store.states["alive"] = beacon.State{ID: "alive", UpdatedAt: time.Now(), Owner: process.Process{PID: os.Getpid()}}
+	store.states["gone"] = beacon.State{ID: "gone", UpdatedAt: time.Now(), Owner: process.Process{PID: 999999999}}
+	var buf bytes.Buffer
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = newMockContextStore()
+	cli.out = &buf
+
+	err := cli.Execute([]string{"gc", "--dead"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	expected := "removed\tgone\tprocess 999999999 exited\n"
+	if buf.String() != expected {
+		t.Errorf("GC output = %q, want %q", buf.String(), expected)
+	}
+	if _, ok := store.states["alive"]; !ok {
+		t.Error("GC --dead removed a beacon with a running owner")
+	}
+}
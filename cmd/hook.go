@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/monochromegane/beacon/internal/adapter"
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+	"github.com/monochromegane/beacon/internal/process"
+)
+
+type HookCmd struct {
+	Claude HookClaudeCmd `cmd:"" help:"Emit or silence from a Claude Code hook payload on stdin"`
+	Codex  HookCodexCmd  `cmd:"" help:"Emit from a Codex CLI notify payload"`
+}
+
+type HookClaudeCmd struct {
+	PID int `name:"pid" help:"PID of the agent process owning the beacon (default: parent process)"`
+}
+
+func (c *HookClaudeCmd) Run(cli *CLI) error {
+	if err := cli.initDefaults(); err != nil {
+		return err
+	}
+	action, err := adapter.ParseClaude(cli.in)
+	if err != nil {
+		return err
+	}
+	return cli.apply(action, c.PID)
+}
+
+type HookCodexCmd struct {
+	Payload string `arg:"" optional:"" help:"JSON payload passed by Codex as the last argument (default: stdin)"`
+	PID     int    `name:"pid" help:"PID of the agent process owning the beacon (default: parent process)"`
+}
+
+func (c *HookCodexCmd) Run(cli *CLI) error {
+	if err := cli.initDefaults(); err != nil {
+		return err
+	}
+	var r io.Reader = cli.in
+	if c.Payload != "" {
+		r = strings.NewReader(c.Payload)
+	}
+	action, err := adapter.ParseCodex(r)
+	if err != nil {
+		return err
+	}
+	return cli.apply(action, c.PID)
+}
+
+// apply performs an action parsed from an agent hook payload. When running
+// in tmux, the pane is added to the context of the session.
+func (c *CLI) apply(action adapter.Action, pid int) error {
+	if action.Op == adapter.OpIgnore {
+		return nil
+	}
+	b, err := c.newBeacon()
+	if err != nil {
+		return err
+	}
+	if action.Op == adapter.OpSilence {
+		return b.Silence(action.ID)
+	}
+
+	if pid == 0 {
+		pid = os.Getppid()
+	}
+	emission := beacon.Emission{
+		Message: action.Message,
+		Status:  action.Status,
+		// The agent reports what it did, so its events are not subject to
+		// the transition rules meant for manual emits.
+		Force:  true,
+		Owner:  process.NewProcFS().Identify(pid),
+		Labels: action.Labels,
+	}
+	if action.Context == nil {
+		return b.Emit(action.ID, emission)
+	}
+	if os.Getenv("TMUX") != "" {
+		if ctx, err := context.NewTmuxProviderWithExecutor(c.executor).GetContext(); err == nil {
+			action.Context.TmuxContext = ctx.(*context.TmuxContext)
+		}
+	}
+	return b.EmitWithContext(action.ID, emission, action.Context)
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// claudePayload opens a recorded Claude Code hook payload.
+func claudePayload(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Open(filepath.Join("..", "internal", "adapter", "testdata", "claude", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestCLI_HookClaude(t *testing.T) {
+	t.Setenv("TMUX", "")
+	const id = "8f0c2a4e-5b1d-4c3e-9a7f-2d6e1b0c9a31"
+	store := newMockStore()
+	contextStore := newMockContextStore()
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = contextStore
+
+	steps := []struct {
+		payload string
+		status  beacon.Status
+		message string
+	}{
+		{"user_prompt_submit.json", beacon.StatusRunning, "Fix the flaky login test"},
+		{"pre_tool_use.json", beacon.StatusRunning, "Fix the flaky login test"},
+		{"notification.json", beacon.StatusWaiting, "Claude needs your permission to use Bash"},
+		{"stop.json", beacon.StatusWaiting, "Claude finished responding"},
+		// A new prompt after the turn ended is always accepted.
+		{"user_prompt_submit.json", beacon.StatusRunning, "Fix the flaky login test"},
+	}
+	for _, step := range steps {
+		cli.in = claudePayload(t, step.payload)
+		if err := cli.Execute([]string{"hook", "claude", "--pid", "1"}); err != nil {
+			t.Fatalf("Execute(%s) error = %v", step.payload, err)
+		}
+		state := store.states[id]
+		if state.Status != step.status || state.Message != step.message || state.Labels["agent"] != "claude" {
+			t.Errorf("after %s state = %+v, want %s %q", step.payload, state, step.status, step.message)
+		}
+	}
+
+	agent, ok := contextStore.contexts[id].(*context.AgentContext)
+	if !ok || agent.Cwd != "/home/dev/src/web" || agent.TranscriptPath == "" || agent.TmuxContext != nil {
+		t.Errorf("context = %#v, want the session without tmux", contextStore.contexts[id])
+	}
+
+	cli.in = claudePayload(t, "session_end.json")
+	if err := cli.Execute([]string{"hook", "claude"}); err != nil {
+		t.Fatalf("Execute(session_end) error = %v", err)
+	}
+	if _, ok := store.states[id]; ok {
+		t.Error("SessionEnd did not silence the beacon")
+	}
+	if _, ok := contextStore.contexts[id]; ok {
+		t.Error("SessionEnd left the context behind")
+	}
+}
+
+func TestCLI_HookClaude_Tmux(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1234,0")
+	contextStore := newMockContextStore()
+	cli := NewCLI()
+	cli.store = newMockStore()
+	cli.contextStore = contextStore
+	cli.executor = &mockExecutor{outputs: map[string]string{"display-message": "main\t2\t1\t%7\t@2\n"}}
+	cli.in = claudePayload(t, "notification.json")
+
+	if err := cli.Execute([]string{"hook", "claude"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	agent, ok := contextStore.contexts["8f0c2a4e-5b1d-4c3e-9a7f-2d6e1b0c9a31"].(*context.AgentContext)
+	if !ok || agent.TmuxContext == nil || agent.PaneID != "%7" || agent.Location() != "main:2.1" {
+		t.Errorf("context = %#v, want the tmux pane", agent)
+	}
+}
+
+func TestCLI_HookCodex(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1234,0")
+	const id = "0199a213-81c0-7800-8aa1-bbab2a035a53"
+	payload, err := os.ReadFile(filepath.Join("..", "internal", "adapter", "testdata", "codex", "agent_turn_complete.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := newMockStore()
+	contextStore := newMockContextStore()
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = contextStore
+	cli.executor = &mockExecutor{outputs: map[string]string{"display-message": "main\t3\t0\t%9\t@3\n"}}
+
+	// Codex passes the payload as the last argument of the notify program.
+	if err := cli.Execute([]string{"hook", "codex", "--pid", "1", string(payload)}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	state := store.states[id]
+	if state.Status != beacon.StatusWaiting || state.Message != "Rename complete and verified `go build ./...` succeeds." || state.Labels["agent"] != "codex" {
+		t.Errorf("state = %+v, want waiting-for-input with the last assistant message", state)
+	}
+	// A finished turn waits for the user, so prompts and status lines count it.
+	b, err := cli.newBeacon()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary, err := b.Summary(); err != nil || summary.Total() != 1 {
+		t.Errorf("Summary() = %+v, %v, want the codex beacon counted", summary, err)
+	}
+	agent, ok := contextStore.contexts[id].(*context.AgentContext)
+	if !ok || agent.Agent != "codex" || agent.Cwd != "/home/dev/src/api" || agent.PaneID != "%9" {
+		t.Errorf("context = %#v, want the codex session in pane %%9", contextStore.contexts[id])
+	}
+
+	// Events other than agent-turn-complete leave the beacon as it is.
+	if err := cli.Execute([]string{"hook", "codex", `{"type":"agent-turn-started","thread-id":"` + id + `"}`}); err != nil {
+		t.Fatalf("Execute(agent-turn-started) error = %v", err)
+	}
+	if got := store.states[id].Status; got != beacon.StatusWaiting {
+		t.Errorf("status after an ignored event = %s, want %s", got, beacon.StatusWaiting)
+	}
+}
+
+func TestCLI_HookClaude_TmuxFilter(t *testing.T) {
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1234,0")
+	cli := NewCLI()
+	cli.store = newMockStore()
+	cli.contextStore = newMockContextStore()
+	cli.executor = &mockExecutor{outputs: map[string]string{"display-message": "api\t2\t1\t%7\t@2\n"}}
+	cli.in = claudePayload(t, "notification.json")
+	if err := cli.Execute([]string{"hook", "claude"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// The pane of an agent session is matched like that of a plain tmux beacon.
+	for _, filter := range []string{"context.tmux.session_name = api", "context.agent.agent = claude"} {
+		var out bytes.Buffer
+		cli.out = &out
+		if err := cli.Execute([]string{"list", "--with-context", "--filter", filter, "-t", "{{.ID}} {{.Context.tmux.session_name}}"}); err != nil {
+			t.Fatalf("Execute(list) error = %v", err)
+		}
+		if want := "8f0c2a4e-5b1d-4c3e-9a7f-2d6e1b0c9a31 api\n"; out.String() != want {
+			t.Errorf("list --filter %q = %q, want %q", filter, out.String(), want)
+		}
+	}
+}
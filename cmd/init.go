@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/monochromegane/beacon/internal/integration"
+)
+
+type InitCmd struct {
+	Shell string `arg:"" help:"Integration target (bash, zsh, fish, tmux)" enum:"bash,zsh,fish,tmux"`
+	Bin   string `name:"bin" help:"Command the snippet uses to run beacon" default:"beacon"`
+}
+
+func (c *InitCmd) Run(cli *CLI) error {
+	script, err := integration.Script(c.Shell, c.Bin)
+	if err != nil {
+		return err
+	}
+	cli.initStreams()
+	_, err = fmt.Fprint(cli.out, script)
+	return err
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCLI_Init(t *testing.T) {
+	var buf bytes.Buffer
+	cli := NewCLI()
+	cli.store = newMockStore()
+	cli.contextStore = newMockContextStore()
+	cli.out = &buf
+
+	if err := cli.Execute([]string{"init", "bash", "--bin", "/opt/my tools/beacon"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "count=$('/opt/my tools/beacon' status --count 2>/dev/null)") {
+		t.Errorf("init bash output does not use --bin:\n%s", buf.String())
+	}
+
+	if err := cli.Execute([]string{"init", "powershell"}); err == nil {
+		t.Error("Execute() expected error for unsupported shell, got nil")
+	}
+}
+
+func TestCLI_Init_IgnoresConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := os.MkdirAll(filepath.Join(dir, "beacon"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "beacon", "config.toml"), []byte("not a config line\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	cli := NewCLI()
+	cli.out = &buf
+
+	// The snippets are static, so a broken config file does not stop them.
+	if err := cli.Execute([]string{"init", "bash"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if buf.Len() == 0 {
+		t.Error("init bash printed nothing")
+	}
+}
@@ -0,0 +1,13 @@
+package cmd
+
+type JumpCmd struct {
+	ID string `arg:"" help:"Session identifier to jump to"`
+}
+
+func (c *JumpCmd) Run(cli *CLI) error {
+	b, err := cli.newBeacon()
+	if err != nil {
+		return err
+	}
+	return b.Jump(c.ID, cli.executor)
+}
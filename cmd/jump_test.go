@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// mockExecutor records executed commands and fails those listed in failures.
+type mockExecutor struct {
+	calls    []string
+	failures map[string]bool
+	// errs maps a subcommand to the error it fails with.
+	errs map[string]error
+	// outputs maps a subcommand such as display-message to its output.
+	outputs map[string]string
+}
+
+func (m *mockExecutor) Execute(name string, args ...string) ([]byte, error) {
+	call := strings.Join(append([]string{name}, args...), " ")
+	m.calls = append(m.calls, call)
+	if err, ok := m.errs[args[0]]; ok {
+		return nil, err
+	}
+	if m.failures[args[0]] {
+		return nil, errors.New(call + " failed")
+	}
+	if output, ok := m.outputs[args[0]]; ok {
+		return []byte(output), nil
+	}
+	return nil, nil
+}
+
+func TestCLI_Jump(t *testing.T) {
+	contextStore := newMockContextStore()
+	contextStore.contexts["test123"] = &context.TmuxContext{SessionName: "main", WindowIndex: 1, PaneIndex: 0, PaneID: "%4"}
+	executor := &mockExecutor{}
+	cli := NewCLI()
+	cli.store = newMockStore()
+	cli.contextStore = contextStore
+	cli.executor = executor
+
+	err := cli.Execute([]string{"jump", "test123"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := "tmux display-message -p -t %4 #{pane_id}|tmux switch-client -t %4|tmux select-window -t %4|tmux select-pane -t %4"
+	if got := strings.Join(executor.calls, "|"); got != want {
+		t.Errorf("Jump executed %q, want %q", got, want)
+	}
+}
+
+func TestCLI_Jump_TargetGone(t *testing.T) {
+	contextStore := newMockContextStore()
+	contextStore.contexts["test123"] = &context.TmuxContext{SessionName: "main", PaneID: "%4"}
+	cli := NewCLI()
+	cli.store = newMockStore()
+	cli.contextStore = contextStore
+	cli.executor = &mockExecutor{errs: map[string]error{"display-message": errors.New("exit status 1: can't find pane: %4")}}
+
+	err := cli.Execute([]string{"jump", "test123"})
+	if !errors.Is(err, context.ErrTargetGone) {
+		t.Errorf("Execute() error = %v, want ErrTargetGone", err)
+	}
+
+	// Other failures, such as a missing tmux server, are reported as they are.
+	cli.executor = &mockExecutor{failures: map[string]bool{"display-message": true}}
+	err = cli.Execute([]string{"jump", "test123"})
+	if err == nil || errors.Is(err, context.ErrTargetGone) {
+		t.Errorf("Execute() error = %v, want an error other than ErrTargetGone", err)
+	}
+}
+
+func TestCLI_Jump_NoContext(t *testing.T) {
+	cli := NewCLI()
+	cli.store = newMockStore()
+	cli.contextStore = newMockContextStore()
+	cli.executor = &mockExecutor{}
+
+	if err := cli.Execute([]string{"jump", "test123"}); err == nil {
+		t.Error("Execute() expected error for beacon without context, got nil")
+	}
+}
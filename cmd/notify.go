@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	stdcontext "context"
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/notify"
+	"github.com/monochromegane/beacon/internal/process"
+	"github.com/monochromegane/beacon/internal/storage"
+)
+
+// NotifyListenCmd waits for the focus action of a desktop notification and
+// jumps to the beacon when it is invoked. Emit starts it in the background.
+type NotifyListenCmd struct {
+	ID           string        `name:"id" required:"" help:"Session identifier"`
+	Notification uint32        `name:"notification" required:"" help:"Notification ID to wait for"`
+	Timeout      time.Duration `name:"timeout" help:"Stop waiting after this duration" default:"24h"`
+}
+
+func (c *NotifyListenCmd) Run(cli *CLI) error {
+	bus, err := notify.DialSession()
+	if err != nil {
+		return err
+	}
+	defer bus.Close()
+
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), c.Timeout)
+	defer cancel()
+	action, err := notify.Listen(ctx, bus, c.Notification)
+	if errors.Is(err, stdcontext.DeadlineExceeded) || (err == nil && action == "") {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	b, err := cli.newBeacon()
+	if err != nil {
+		return err
+	}
+	return b.Jump(c.ID, cli.executor)
+}
+
+// listenerSpawner returns a notify.Spawner starting notify-listen for a
+// notification, detached from the terminal of the emitting process. The
+// listener looks the beacon up at loc.
+func listenerSpawner(loc storage.Location) notify.Spawner {
+	return func(id string, notificationID uint32) (process.Process, error) {
+		return spawnListener(loc, id, notificationID)
+	}
+}
+
+func spawnListener(loc storage.Location, id string, notificationID uint32) (process.Process, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return process.Process{}, err
+	}
+	args := []string{"notify-listen", "--id=" + id,
+		"--notification=" + strconv.FormatUint(uint64(notificationID), 10)}
+	if loc.BaseDir != "" {
+		args = append(args, "--base-dir="+loc.BaseDir)
+	}
+	if loc.Profile != "" {
+		args = append(args, "--profile="+loc.Profile)
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return process.Process{}, err
+	}
+	pid := cmd.Process.Pid
+	cmd.Process.Release()
+	return process.NewProcFS().Identify(pid), nil
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+	"github.com/monochromegane/beacon/internal/picker"
+)
+
+type PickCmd struct{}
+
+func (c *PickCmd) Run(cli *CLI) error {
+	b, err := cli.newBeacon()
+	if err != nil {
+		return err
+	}
+	items, err := cli.pickerItems(b)
+	if err != nil {
+		return err
+	}
+	return picker.Run(items, picker.Options{
+		OnJump: func(id string) error {
+			return b.Jump(id, cli.executor)
+		},
+		OnSilence: b.Silence,
+	})
+}
+
+// pickerItems builds picker entries for active beacons, most recently updated first.
+func (c *CLI) pickerItems(b *beacon.Beacon) ([]picker.Item, error) {
+	states, err := b.List()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(states, func(i, j int) bool { return states[i].UpdatedAt.After(states[j].UpdatedAt) })
+
+	items := make([]picker.Item, 0, len(states))
+	for _, state := range states {
+		item := picker.Item{
+			ID:      state.ID,
+			Status:  string(state.Status),
+			Message: state.Message,
+		}
+		if data, err := c.contextStore.Read(state.ID); err == nil {
+			var preview bytes.Buffer
+			if json.Indent(&preview, data, "", "  ") == nil {
+				item.Preview = preview.String()
+			}
+			if ctx, err := context.Decode(data); err == nil {
+				if locator, ok := ctx.(context.Locator); ok {
+					item.Location = locator.Location()
+				}
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
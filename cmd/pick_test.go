@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+func TestCLI_PickerItems(t *testing.T) {
+	now := time.Now()
+	store := newMockStore()
+	store.states["older"] = beacon.State{ID: "older", Status: beacon.StatusRunning, Message: "busy", UpdatedAt: now.Add(-time.Minute)}
+	store.states["newer"] = beacon.State{ID: "newer", Status: beacon.StatusWaiting, Message: "approve?", UpdatedAt: now}
+	store.states["expired"] = beacon.State{ID: "expired", UpdatedAt: now, ExpiresAt: now.Add(-time.Second)}
+	contextStore := newMockContextStore()
+	contextStore.contexts["newer"] = &context.TmuxContext{SessionName: "api", WindowIndex: 1, PaneIndex: 2, PaneID: "%3"}
+	cli := NewCLI()
+	cli.store = store
+	cli.contextStore = contextStore
+
+	b, err := cli.newBeacon()
+	if err != nil {
+		t.Fatalf("newBeacon() error = %v", err)
+	}
+	items, err := cli.pickerItems(b)
+	if err != nil {
+		t.Fatalf("pickerItems() error = %v", err)
+	}
+
+	if len(items) != 2 {
+		t.Fatalf("pickerItems() len = %d, want 2", len(items))
+	}
+	if items[0].ID != "newer" || items[1].ID != "older" {
+		t.Errorf("pickerItems() order = %s, %s, want newer, older", items[0].ID, items[1].ID)
+	}
+	if items[0].Location != "api:1.2" || items[0].Status != "waiting-for-input" {
+		t.Errorf("pickerItems()[0] = %+v", items[0])
+	}
+	expectedPreview := "{\n  \"session_name\": \"api\",\n  \"window_index\": 1,\n  \"pane_index\": 2,\n  \"pane_id\": \"%3\"\n}"
+	if items[0].Preview != expectedPreview {
+		t.Errorf("pickerItems()[0].Preview = %q, want %q", items[0].Preview, expectedPreview)
+	}
+	if items[1].Location != "" || items[1].Preview != "" {
+		t.Errorf("pickerItems()[1] without context = %+v", items[1])
+	}
+}
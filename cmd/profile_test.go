@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/monochromegane/beacon/internal/config"
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+func TestCLI_Profiles(t *testing.T) {
+	baseDir := t.TempDir()
+	t.Setenv("BEACON_DIR", "")
+	t.Setenv("BEACON_PROFILE", "")
+
+	run := func(args ...string) string {
+		t.Helper()
+		var buf bytes.Buffer
+		cli := NewCLI()
+		cli.config = &config.Config{}
+		cli.out = &buf
+		if err := cli.Execute(args); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+		return buf.String()
+	}
+
+	run("emit", "--base-dir", baseDir, "--id", "personal", "experiment")
+	run("--base-dir", baseDir, "--profile", "work", "emit", "--id", "api", "deploy?")
+	t.Setenv("BEACON_PROFILE", "work")
+	run("emit", "--base-dir", baseDir, "--id", "web", "review?")
+	t.Setenv("BEACON_PROFILE", "")
+
+	if got, want := run("list", "--base-dir", baseDir, "-f", "tsv"), "personal\twaiting-for-input\texperiment\n"; got != want {
+		t.Errorf("list of the default profile = %q, want %q", got, want)
+	}
+	want := "api\twaiting-for-input\tdeploy?\nweb\twaiting-for-input\treview?\n"
+	if got := run("list", "--base-dir", baseDir, "--profile", "work", "-f", "tsv"); got != want {
+		t.Errorf("list of the work profile = %q, want %q", got, want)
+	}
+	want = "default\tpersonal\twaiting-for-input\texperiment\n" +
+		"work\tapi\twaiting-for-input\tdeploy?\n" +
+		"work\tweb\twaiting-for-input\treview?\n"
+	if got := run("list", "--base-dir", baseDir, "--all-profiles", "-f", "tsv"); got != want {
+		t.Errorf("list --all-profiles = %q, want %q", got, want)
+	}
+	if got, want := run("list", "--base-dir", baseDir, "--all-profiles", "--filter", "profile = work", "-t", "{{.ID}}"), "api\nweb\n"; got != want {
+		t.Errorf("list --all-profiles --filter = %q, want %q", got, want)
+	}
+
+	run("silence", "--base-dir", baseDir, "--profile", "work", "--id", "api")
+	if _, err := os.Stat(filepath.Join(baseDir, ".profiles", "work", "api")); !os.IsNotExist(err) {
+		t.Errorf("silence in the work profile left its beacon: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "personal")); err != nil {
+		t.Errorf("beacon of the default profile is gone: %v", err)
+	}
+}
+
+func TestCLI_Location_Precedence(t *testing.T) {
+	flagDir, envDir, fileDir := t.TempDir(), t.TempDir(), t.TempDir()
+	t.Setenv("BEACON_PROFILE", "")
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want string
+	}{
+		{name: "file", want: fileDir},
+		{name: "env over file", env: envDir, want: envDir},
+		{name: "flag over env", env: envDir, args: []string{"--base-dir", flagDir}, want: flagDir},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BEACON_DIR", tt.env)
+			if tt.env == "" {
+				os.Unsetenv("BEACON_DIR")
+			}
+			cli := NewCLI()
+			cli.config = &config.Config{Storage: config.StorageConfig{BaseDir: fileDir, Profile: "work"}}
+			cli.out = &bytes.Buffer{}
+
+			if err := cli.Execute(append(tt.args, "emit", "--id", "test123", "message")); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			// The context store resolves the same directory as the beacon store.
+			if err := cli.contextStore.Write("test123", &context.TmuxContext{PaneID: "%1"}); err != nil {
+				t.Fatal(err)
+			}
+			dir := filepath.Join(tt.want, ".profiles", "work")
+			for _, name := range []string{"test123", "test123.json"} {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("%s is not in %s: %v", name, dir, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCLI_InvalidProfile(t *testing.T) {
+	cli := NewCLI()
+	cli.config = &config.Config{}
+	cli.out = &bytes.Buffer{}
+
+	err := cli.Execute([]string{"emit", "--base-dir", t.TempDir(), "--profile", "../up", "--id", "test123", "message"})
+	if err == nil || err.Error() != `invalid profile "../up": use letters, digits, '-' and '_'` {
+		t.Errorf("Execute() error = %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/monochromegane/beacon/internal/render"
+)
+
+type StatusCmd struct {
+	Tmux  bool `name:"tmux" help:"Print a tmux status-line segment with style codes (empty when nothing waits)" xor:"format"`
+	Count bool `name:"count" help:"Print only the number of beacons needing attention" xor:"format"`
+}
+
+func (c *StatusCmd) Run(cli *CLI) error {
+	b, err := cli.newBeacon()
+	if err != nil {
+		return err
+	}
+	summary, err := b.Summary()
+	if err != nil {
+		return err
+	}
+	if c.Count {
+		_, err := fmt.Fprintln(cli.out, summary.Total())
+		return err
+	}
+	return render.Summary(cli.out, summary, c.Tmux)
+}
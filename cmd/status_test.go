@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+func TestCLI_Status(t *testing.T) {
+	store := newMockStore()
+	store.states["a"] = beacon.State{ID: "a", Status: beacon.StatusWaiting}
+	store.states["b"] = beacon.State{ID: "b", Status: beacon.StatusBlocked}
+	store.states["c"] = beacon.State{ID: "c", Status: beacon.StatusRunning}
+	contextStore := newMockContextStore()
+	contextStore.contexts["a"] = &context.TmuxContext{SessionName: "api", PaneID: "%1"}
+	contextStore.contexts["b"] = &context.TmuxContext{SessionName: "api", PaneID: "%2"}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"status"}, "1 waiting 1 blocked api:2\n"},
+		{[]string{"status", "--count"}, "2\n"},
+		{[]string{"status", "--tmux"}, "#[fg=yellow]1 waiting#[default] #[fg=red]1 blocked#[default] api:2\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		cli := NewCLI()
+		cli.store = store
+		cli.contextStore = contextStore
+		cli.out = &buf
+
+		if err := cli.Execute(tt.args); err != nil {
+			t.Fatalf("Execute(%v) error = %v", tt.args, err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Execute(%v) output = %q, want %q", tt.args, buf.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/template"
+)
+
+type WatchCmd struct {
+	Template string `name:"template" short:"t" help:"Go text/template string applied to each event instead of NDJSON" default:""`
+}
+
+func (c *WatchCmd) Run(cli *CLI) error {
+	b, err := cli.newBeacon()
+	if err != nil {
+		return err
+	}
+
+	var tmpl *template.Template
+	if c.Template != "" {
+		tmpl, err = template.New("watch").Parse(c.Template)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	events, err := b.Watch(ctx)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(cli.out)
+	for event := range events {
+		if tmpl == nil {
+			if err := enc.Encode(event); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := tmpl.Execute(cli.out, event); err != nil {
+			return err
+		}
+		fmt.Fprintln(cli.out)
+	}
+	return nil
+}
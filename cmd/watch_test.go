@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+)
+
+type mockWatchStore struct {
+	*mockStore
+	events []beacon.Event
+}
+
+func (m *mockWatchStore) Watch(ctx context.Context) (<-chan beacon.Event, error) {
+	ch := make(chan beacon.Event, len(m.events))
+	for _, event := range m.events {
+		ch <- event
+	}
+	close(ch)
+	return ch, nil
+}
+
+func newMockWatchStore() *mockWatchStore {
+	at := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	return &mockWatchStore{
+		mockStore: newMockStore(),
+		events: []beacon.Event{
+			{Type: beacon.EventAdded, ID: "test123", State: beacon.State{Version: 1, ID: "test123", Message: "hello", Status: beacon.StatusWaiting, CreatedAt: at, UpdatedAt: at, EmitCount: 1}},
+			{Type: beacon.EventRemoved, ID: "test123", State: beacon.State{Version: 1, ID: "test123", Message: "hello", Status: beacon.StatusWaiting, CreatedAt: at, UpdatedAt: at, EmitCount: 1}},
+		},
+	}
+}
+
+func TestCLI_Watch_NDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	cli := NewCLI()
+	cli.store = newMockWatchStore()
+	cli.contextStore = newMockContextStore()
+	cli.out = &buf
+
+	err := cli.Execute([]string{"watch"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	state := `"state":{"version":1,"id":"test123","message":"hello","status":"waiting-for-input","created_at":"2026-01-01T10:00:00Z","updated_at":"2026-01-01T10:00:00Z","emit_count":1,"emitter_pid":0,"hostname":""}`
+	expected := `{"type":"added","id":"test123",` + state + "}\n" +
+		`{"type":"removed","id":"test123",` + state + "}\n"
+	if buf.String() != expected {
+		t.Errorf("Watch output = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestCLI_Watch_Template(t *testing.T) {
+	var buf bytes.Buffer
+	cli := NewCLI()
+	cli.store = newMockWatchStore()
+	cli.contextStore = newMockContextStore()
+	cli.out = &buf
+
+	err := cli.Execute([]string{"watch", "--template", "{{.Type}} {{.ID}}: {{.State.Message}}"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	expected := "added test123: hello\nremoved test123: hello\n"
+	if buf.String() != expected {
+		t.Errorf("Watch output = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestCLI_Watch_Unsupported(t *testing.T) {
+	cli := NewCLI()
+	cli.store = newMockStore()
+	cli.contextStore = newMockContextStore()
+	cli.out = &bytes.Buffer{}
+
+	err := cli.Execute([]string{"watch"})
+	if err == nil {
+		t.Error("Execute() expected error for store without Watch, got nil")
+	}
+}
@@ -2,4 +2,9 @@ module github.com/monochromegane/beacon
 
 go 1.25.5
 
-require github.com/alecthomas/kong v1.13.0
+require (
+	github.com/alecthomas/kong v1.13.0
+	github.com/godbus/dbus/v5 v5.2.2
+)
+
+require golang.org/x/sys v0.27.0 // indirect
@@ -0,0 +1,49 @@
+// Package adapter turns the hook and notify payloads of coding agents, such
+// as Claude Code and Codex CLI, into beacon emits and silences. Other tools
+// are mapped with path expressions into their JSON output.
+package adapter
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// Op is what a payload asks beacon to do.
+type Op string
+
+const (
+	// OpIgnore is returned for events that do not change the beacon.
+	OpIgnore  Op = ""
+	OpEmit    Op = "emit"
+	OpSilence Op = "silence"
+)
+
+// Action is a payload translated into a beacon operation.
+type Action struct {
+	Op      Op
+	ID      string
+	Message string
+	Status  beacon.Status
+	Labels  beacon.Labels
+	// Context describes the agent session. The caller adds the tmux pane.
+	Context *context.AgentContext
+}
+
+// maxMessageLen caps messages taken from free text such as prompts.
+const maxMessageLen = 80
+
+// firstLine returns the first non-empty line of s, shortened to maxMessageLen runes.
+func firstLine(s string) string {
+	for line := range strings.Lines(s) {
+		if line = strings.TrimSpace(line); line != "" {
+			if utf8.RuneCountInString(line) > maxMessageLen {
+				line = string([]rune(line)[:maxMessageLen-1]) + "…"
+			}
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// claudePayload holds the fields beacon uses from a Claude Code hook payload,
+// which every hook receives on stdin.
+type claudePayload struct {
+	SessionID      string `json:"session_id"`
+	TranscriptPath string `json:"transcript_path"`
+	Cwd            string `json:"cwd"`
+	HookEventName  string `json:"hook_event_name"`
+	// Message is the text of a Notification.
+	Message string `json:"message"`
+	// Prompt is the text of a UserPromptSubmit.
+	Prompt string `json:"prompt"`
+}
+
+// ParseClaude reads a Claude Code hook payload from r:
+//
+//   - Notification emits waiting-for-input with the notification text.
+//   - UserPromptSubmit emits running with the first line of the prompt.
+//   - SubagentStop emits running, as the main agent carries on.
+//   - Stop emits waiting-for-input, as Claude waits for the next prompt.
+//   - SessionEnd silences the beacon.
+//
+// The session ID is the beacon ID. Other events are ignored.
+func ParseClaude(r io.Reader) (Action, error) {
+	var p claudePayload
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return Action{}, fmt.Errorf("claude hook payload: %w", err)
+	}
+	if p.HookEventName == "" {
+		return Action{}, errors.New("claude hook payload: hook_event_name is missing")
+	}
+
+	action := Action{Op: OpEmit}
+	switch p.HookEventName {
+	case "Notification":
+		action.Status = beacon.StatusWaiting
+		action.Message = p.Message
+		if action.Message == "" {
+			action.Message = "Claude needs your attention"
+		}
+	case "UserPromptSubmit":
+		action.Status = beacon.StatusRunning
+		action.Message = firstLine(p.Prompt)
+	case "SubagentStop":
+		action.Status = beacon.StatusRunning
+		action.Message = "Subagent finished"
+	case "Stop":
+		action.Status = beacon.StatusWaiting
+		action.Message = "Claude finished responding"
+	case "SessionEnd":
+		action = Action{Op: OpSilence}
+	default:
+		return Action{Op: OpIgnore}, nil
+	}
+
+	if p.SessionID == "" {
+		return Action{}, fmt.Errorf("claude hook payload: session_id is missing for %s", p.HookEventName)
+	}
+	action.ID = p.SessionID
+	if action.Op == OpEmit {
+		action.Labels = beacon.Labels{"agent": "claude"}
+		action.Context = &context.AgentContext{Agent: "claude", Cwd: p.Cwd, TranscriptPath: p.TranscriptPath}
+	}
+	return action, nil
+}
@@ -0,0 +1,81 @@
+package adapter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+func TestParseClaude(t *testing.T) {
+	const sessionID = "8f0c2a4e-5b1d-4c3e-9a7f-2d6e1b0c9a31"
+	session := &context.AgentContext{
+		Agent:          "claude",
+		Cwd:            "/home/dev/src/web",
+		TranscriptPath: "/home/dev/.claude/projects/-home-dev-src-web/" + sessionID + ".jsonl",
+	}
+	labels := beacon.Labels{"agent": "claude"}
+	tests := []struct {
+		file string
+		want Action
+	}{
+		{"notification.json", Action{Op: OpEmit, ID: sessionID, Status: beacon.StatusWaiting, Message: "Claude needs your permission to use Bash", Labels: labels, Context: session}},
+		{"user_prompt_submit.json", Action{Op: OpEmit, ID: sessionID, Status: beacon.StatusRunning, Message: "Fix the flaky login test", Labels: labels, Context: session}},
+		{"subagent_stop.json", Action{Op: OpEmit, ID: sessionID, Status: beacon.StatusRunning, Message: "Subagent finished", Labels: labels, Context: session}},
+		{"stop.json", Action{Op: OpEmit, ID: sessionID, Status: beacon.StatusWaiting, Message: "Claude finished responding", Labels: labels, Context: session}},
+		{"session_end.json", Action{Op: OpSilence, ID: sessionID}},
+		{"pre_tool_use.json", Action{Op: OpIgnore}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "claude", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			got, err := ParseClaude(f)
+			if err != nil {
+				t.Fatalf("ParseClaude() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseClaude() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseClaude_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`not json`, "claude hook payload: invalid character"},
+		{`{"session_id":"s1"}`, "claude hook payload: hook_event_name is missing"},
+		{`{"hook_event_name":"Stop"}`, "claude hook payload: session_id is missing for Stop"},
+	}
+	for _, tt := range tests {
+		_, err := ParseClaude(strings.NewReader(tt.input))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("ParseClaude(%q) error = %v, want prefix %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestFirstLine(t *testing.T) {
+	long := strings.Repeat("a", 100)
+	tests := map[string]string{
+		"\n\n  fix it  \nmore": "fix it",
+		"":                     "",
+		long:                   strings.Repeat("a", 79) + "…",
+	}
+	for input, want := range tests {
+		if got := firstLine(input); got != want {
+			t.Errorf("firstLine(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// codexPayload holds the fields beacon uses from the JSON argument that
+// Codex CLI passes to its notify program.
+type codexPayload struct {
+	Type     string `json:"type"`
+	ThreadID string `json:"thread-id"`
+	// SessionID is set by Codex versions that predate threads.
+	SessionID            string `json:"session-id"`
+	Cwd                  string `json:"cwd"`
+	LastAssistantMessage string `json:"last-assistant-message"`
+}
+
+// ParseCodex reads a Codex CLI notify payload from r. An agent-turn-complete
+// emits waiting-for-input with the first line of the last assistant message,
+// as Codex waits for the next prompt. The thread ID, or the session ID of
+// older versions, is the beacon ID. Other events are ignored.
+func ParseCodex(r io.Reader) (Action, error) {
+	var p codexPayload
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return Action{}, fmt.Errorf("codex notify payload: %w", err)
+	}
+	if p.Type == "" {
+		return Action{}, errors.New("codex notify payload: type is missing")
+	}
+	if p.Type != "agent-turn-complete" {
+		return Action{Op: OpIgnore}, nil
+	}
+
+	id := p.ThreadID
+	if id == "" {
+		id = p.SessionID
+	}
+	if id == "" {
+		return Action{}, fmt.Errorf("codex notify payload: thread-id is missing for %s", p.Type)
+	}
+	message := firstLine(p.LastAssistantMessage)
+	if message == "" {
+		message = "Codex finished responding"
+	}
+	return Action{
+		Op:      OpEmit,
+		ID:      id,
+		Message: message,
+		Status:  beacon.StatusWaiting,
+		Labels:  beacon.Labels{"agent": "codex"},
+		Context: &context.AgentContext{Agent: "codex", Cwd: p.Cwd},
+	}, nil
+}
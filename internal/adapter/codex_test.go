@@ -0,0 +1,59 @@
+package adapter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+func TestParseCodex(t *testing.T) {
+	session := &context.AgentContext{Agent: "codex", Cwd: "/home/dev/src/api"}
+	labels := beacon.Labels{"agent": "codex"}
+	tests := []struct {
+		file string
+		want Action
+	}{
+		{"agent_turn_complete.json", Action{Op: OpEmit, ID: "0199a213-81c0-7800-8aa1-bbab2a035a53", Status: beacon.StatusWaiting, Message: "Rename complete and verified `go build ./...` succeeds.", Labels: labels, Context: session}},
+		{"agent_turn_complete_session.json", Action{Op: OpEmit, ID: "5973b6c0-94b8-487b-a530-2aeb6098ae0e", Status: beacon.StatusWaiting, Message: "Codex finished responding", Labels: labels, Context: session}},
+		{"unknown_type.json", Action{Op: OpIgnore}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", "codex", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			got, err := ParseCodex(f)
+			if err != nil {
+				t.Fatalf("ParseCodex() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCodex() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCodex_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`not json`, "codex notify payload: invalid character"},
+		{`{"thread-id":"t1"}`, "codex notify payload: type is missing"},
+		{`{"type":"agent-turn-complete"}`, "codex notify payload: thread-id is missing for agent-turn-complete"},
+	}
+	for _, tt := range tests {
+		_, err := ParseCodex(strings.NewReader(tt.input))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("ParseCodex(%q) error = %v, want prefix %q", tt.input, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package adapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Mapping describes where the parts of a beacon are found in a JSON document
+// from a tool that has no adapter of its own. Unset fields are left for the
+// caller to fill in from its own flags.
+type Mapping struct {
+	// IDPath selects the beacon ID.
+	IDPath string
+	// MessagePath selects the message.
+	MessagePath string
+	// MessageTemplate is a Go text/template applied to the document to build
+	// the message. It takes precedence over MessagePath.
+	MessageTemplate string
+	// SilenceIf selects a value that, when true, silences the beacon instead.
+	SilenceIf string
+}
+
+// Parse reads a JSON document from r and applies the mapping to it.
+func (m Mapping) Parse(r io.Reader) (Action, error) {
+	idPath, err := parseOptionalPath(m.IDPath)
+	if err != nil {
+		return Action{}, err
+	}
+	messagePath, err := parseOptionalPath(m.MessagePath)
+	if err != nil {
+		return Action{}, err
+	}
+	silenceIf, err := parseOptionalPath(m.SilenceIf)
+	if err != nil {
+		return Action{}, err
+	}
+	var tmpl *template.Template
+	if m.MessageTemplate != "" {
+		if tmpl, err = template.New("message").Option("missingkey=error").Parse(m.MessageTemplate); err != nil {
+			return Action{}, err
+		}
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return Action{}, fmt.Errorf("json input: %w", err)
+	}
+
+	action := Action{Op: OpEmit}
+	if idPath != nil {
+		id, err := idPath.LookupString(doc)
+		if err != nil {
+			return Action{}, err
+		}
+		if id == "" {
+			return Action{}, fmt.Errorf("%s is empty", idPath)
+		}
+		action.ID = id
+	}
+	if silenceIf != nil {
+		silence, err := silenceIf.LookupBool(doc)
+		if err != nil {
+			return Action{}, err
+		}
+		if silence {
+			action.Op = OpSilence
+			return action, nil
+		}
+	}
+	switch {
+	case tmpl != nil:
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, doc); err != nil {
+			return Action{}, err
+		}
+		action.Message = strings.TrimSpace(buf.String())
+	case messagePath != nil:
+		message, err := messagePath.LookupString(doc)
+		if err != nil {
+			return Action{}, err
+		}
+		action.Message = message
+	}
+	return action, nil
+}
+
+// parseOptionalPath parses s, returning nil if it is empty.
+func parseOptionalPath(s string) (*Path, error) {
+	if s == "" {
+		return nil, nil
+	}
+	p, err := ParsePath(s)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
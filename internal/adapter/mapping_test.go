@@ -0,0 +1,74 @@
+package adapter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMapping_Parse(t *testing.T) {
+	const input = `{"session":{"id":"build-42"},"msg":"Tests passed","repo":"web","done":false}`
+	tests := []struct {
+		name    string
+		mapping Mapping
+		input   string
+		want    Action
+	}{
+		{
+			name:    "paths",
+			mapping: Mapping{IDPath: ".session.id", MessagePath: ".msg", SilenceIf: ".done"},
+			input:   input,
+			want:    Action{Op: OpEmit, ID: "build-42", Message: "Tests passed"},
+		},
+		{
+			name:    "template",
+			mapping: Mapping{IDPath: ".session.id", MessageTemplate: "{{.repo}}: {{.msg}}\n"},
+			input:   input,
+			want:    Action{Op: OpEmit, ID: "build-42", Message: "web: Tests passed"},
+		},
+		{
+			name:    "silence",
+			mapping: Mapping{IDPath: ".session.id", MessagePath: ".msg", SilenceIf: ".done"},
+			input:   `{"session":{"id":"build-42"},"done":true}`,
+			want:    Action{Op: OpSilence, ID: "build-42"},
+		},
+		{
+			name:    "id left to the caller",
+			mapping: Mapping{MessagePath: ".msg"},
+			input:   input,
+			want:    Action{Op: OpEmit, Message: "Tests passed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.mapping.Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapping_Parse_Errors(t *testing.T) {
+	tests := []struct {
+		mapping Mapping
+		input   string
+		want    string
+	}{
+		{Mapping{IDPath: ".session.id"}, `{"session":{}}`, `.session.id did not resolve: .session has no key "id"`},
+		{Mapping{IDPath: ".id"}, `{"id":""}`, ".id is empty"},
+		{Mapping{MessagePath: ".msg"}, `{}`, `.msg did not resolve: . has no key "msg"`},
+		{Mapping{MessageTemplate: "{{.msg}}"}, `{}`, `map has no entry for key "msg"`},
+		{Mapping{IDPath: "id"}, `{}`, `invalid path "id": must start with "."`},
+		{Mapping{IDPath: ".id"}, `not json`, "json input: invalid character"},
+	}
+	for _, tt := range tests {
+		_, err := tt.mapping.Parse(strings.NewReader(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%+v, %s) error = %v, want %q", tt.mapping, tt.input, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathError reports the step at which a path did not resolve.
+type pathError struct {
+	path   Path
+	reason string
+	// missing is set when the step names a key or index that is absent.
+	missing bool
+}
+
+func (e *pathError) Error() string {
+	return fmt.Sprintf("%s did not resolve: %s", e.path, e.reason)
+}
+
+// Path selects a value in a JSON document with a subset of jq syntax:
+// "." is the whole document, ".session.id" a nested key and ".items[0]" an
+// array element.
+type Path struct {
+	text  string
+	steps []pathStep
+}
+
+type pathStep struct {
+	key   string
+	index int
+	// isIndex distinguishes [0] from a key.
+	isIndex bool
+}
+
+// ParsePath parses a path expression such as .session.id or .items[0].name.
+func ParsePath(s string) (Path, error) {
+	if !strings.HasPrefix(s, ".") {
+		return Path{}, fmt.Errorf("invalid path %q: must start with \".\"", s)
+	}
+	p := Path{text: s}
+	rest := s
+	if rest == "." {
+		return p, nil
+	}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return Path{}, fmt.Errorf("invalid path %q: empty key", s)
+			}
+			p.steps = append(p.steps, pathStep{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return Path{}, fmt.Errorf("invalid path %q: missing \"]\"", s)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return Path{}, fmt.Errorf("invalid path %q: index %q is not a non-negative number", s, rest[1:end])
+			}
+			p.steps = append(p.steps, pathStep{index: index, isIndex: true})
+			rest = rest[end+1:]
+		default:
+			return Path{}, fmt.Errorf("invalid path %q: unexpected %q", s, rest[0])
+		}
+	}
+	return p, nil
+}
+
+// String returns the path as it was written.
+func (p Path) String() string {
+	return p.text
+}
+
+// Lookup returns the value p selects in doc, a document decoded by
+// encoding/json. The error names the path and the step that did not resolve.
+func (p Path) Lookup(doc any) (any, error) {
+	v := doc
+	var walked strings.Builder
+	for _, step := range p.steps {
+		at := walked.String()
+		if at == "" {
+			at = "."
+		}
+		if step.isIndex {
+			array, ok := v.([]any)
+			if !ok {
+				return nil, &pathError{path: p, reason: fmt.Sprintf("%s is %s, not an array", at, kind(v))}
+			}
+			if step.index >= len(array) {
+				return nil, &pathError{path: p, reason: fmt.Sprintf("%s has %d elements", at, len(array)), missing: true}
+			}
+			v = array[step.index]
+			fmt.Fprintf(&walked, "[%d]", step.index)
+			continue
+		}
+		object, ok := v.(map[string]any)
+		if !ok {
+			return nil, &pathError{path: p, reason: fmt.Sprintf("%s is %s, not an object", at, kind(v))}
+		}
+		if v, ok = object[step.key]; !ok {
+			return nil, &pathError{path: p, reason: fmt.Sprintf("%s has no key %q", at, step.key), missing: true}
+		}
+		walked.WriteString("." + step.key)
+	}
+	return v, nil
+}
+
+// LookupString returns the scalar value p selects in doc as text.
+func (p Path) LookupString(doc any) (string, error) {
+	v, err := p.Lookup(doc)
+	if err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("%s is %s, not a string", p, kind(v))
+	}
+}
+
+// LookupBool reports whether p selects a true value in doc. Missing values
+// and null are false, numbers are true unless they are 0, and strings are
+// read as by strconv.ParseBool, so "false" and "0" are false, as is the
+// empty string. Other strings, objects and arrays are an error.
+func (p Path) LookupBool(doc any) (bool, error) {
+	v, err := p.Lookup(doc)
+	var pe *pathError
+	if errors.As(err, &pe) && pe.missing {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	switch v := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		if v == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("%s is %q, not a boolean", p, v)
+		}
+		return b, nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return false, fmt.Errorf("%s is %s, not a usable number: %w", p, v, err)
+		}
+		return f != 0, nil
+	case float64:
+		return v != 0, nil
+	default:
+		return false, fmt.Errorf("%s is %s, not a boolean", p, kind(v))
+	}
+}
+
+// kind describes the JSON type of v for error messages.
+func kind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case json.Number, float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
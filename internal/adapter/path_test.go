@@ -0,0 +1,102 @@
+package adapter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestParsePath_Errors(t *testing.T) {
+	tests := map[string]string{
+		"session.id":   `invalid path "session.id": must start with "."`,
+		".session..id": `invalid path ".session..id": empty key`,
+		".items[0":     `invalid path ".items[0": missing "]"`,
+		".items[-1]":   `invalid path ".items[-1]": index "-1" is not a non-negative number`,
+		".items[0]x":   `invalid path ".items[0]x": unexpected 'x'`,
+	}
+	for input, want := range tests {
+		if _, err := ParsePath(input); err == nil || err.Error() != want {
+			t.Errorf("ParsePath(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
+
+func TestPath_LookupString(t *testing.T) {
+	doc := decode(t, `{"session":{"id":"s1","turn":12},"items":[{"name":"a"},{"name":"b"}],"ok":true,"nothing":null}`)
+	tests := []struct {
+		path    string
+		want    string
+		wantErr string
+	}{
+		{path: ".session.id", want: "s1"},
+		{path: ".session.turn", want: "12"},
+		{path: ".items[1].name", want: "b"},
+		{path: ".ok", want: "true"},
+		{path: ".session.name", wantErr: `.session.name did not resolve: .session has no key "name"`},
+		{path: ".items[2].name", wantErr: ".items[2].name did not resolve: .items has 2 elements"},
+		{path: ".session.id.value", wantErr: ".session.id.value did not resolve: .session.id is a string, not an object"},
+		{path: ".session[0]", wantErr: ".session[0] did not resolve: .session is an object, not an array"},
+		{path: ".missing", wantErr: `.missing did not resolve: . has no key "missing"`},
+		{path: ".session", wantErr: ".session is an object, not a string"},
+		{path: ".nothing", wantErr: ".nothing is null, not a string"},
+	}
+	for _, tt := range tests {
+		p, err := ParsePath(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := p.LookupString(doc)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("LookupString(%s) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("LookupString(%s) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
+
+func TestPath_LookupBool(t *testing.T) {
+	doc := decode(t, `{"yes":true,"no":false,"one":1,"zero":0,"true":"true","false":"false","text_one":"1","text_zero":"0","empty":"","nothing":null}`)
+	tests := map[string]bool{
+		".yes": true, ".no": false, ".one": true, ".zero": false,
+		".true": true, ".false": false, ".text_one": true, ".text_zero": false,
+		".empty": false, ".nothing": false, ".missing": false,
+	}
+	for path, want := range tests {
+		p, err := ParsePath(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := p.LookupBool(doc); err != nil || got != want {
+			t.Errorf("LookupBool(%s) = %v, %v, want %v", path, got, err, want)
+		}
+	}
+}
+
+func TestPath_LookupBool_Error(t *testing.T) {
+	doc := decode(t, `{"text":"x","list":[],"object":{}}`)
+	doc.(map[string]any)["huge"] = json.Number("1e999")
+	for _, path := range []string{".text", ".list", ".object", ".huge", ".text.done"} {
+		p, err := ParsePath(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := p.LookupBool(doc); err == nil {
+			t.Errorf("LookupBool(%s) = %v, want an error", path, got)
+		}
+	}
+}
@@ -2,70 +2,164 @@ package beacon
 
 import (
 	"fmt"
-	"io"
+	"maps"
+	"sort"
+	"time"
 
 	"github.com/monochromegane/beacon/internal/context"
+	"github.com/monochromegane/beacon/internal/process"
 )
 
 // Beacon provides the core business logic for managing beacon state files.
 type Beacon struct {
 	store        Store
 	contextStore context.ContextStore
-	out          io.Writer
+	now          func() time.Time
+	processes    ProcessChecker
+	panes        PaneLister
+	indicators   *indicatorSettings
+	notifiers    []Notifier
+	notifyErr    func(error)
 }
 
-// New creates a new Beacon with the given store and output writer.
-func New(store Store, out io.Writer) *Beacon {
+// PaneLister lists the panes that exist on the tmux server.
+type PaneLister interface {
+	ListPanes() (map[string]bool, error)
+}
+
+// ProcessChecker reports whether the process owning a beacon is still running.
+type ProcessChecker interface {
+	Alive(proc process.Process) (bool, error)
+}
+
+// New creates a new Beacon with the given store.
+func New(store Store) *Beacon {
 	return &Beacon{
-		store: store,
-		out:   out,
+		store:     store,
+		now:       time.Now,
+		processes: process.NewProcFS(),
+		panes:     context.NewTmuxProvider(),
 	}
 }
 
-// NewWithContextStore creates a new Beacon with both stores and output writer.
-func NewWithContextStore(store Store, contextStore context.ContextStore, out io.Writer) *Beacon {
+// NewWithContextStore creates a new Beacon with both stores.
+func NewWithContextStore(store Store, contextStore context.ContextStore) *Beacon {
 	return &Beacon{
 		store:        store,
 		contextStore: contextStore,
-		out:          out,
+		now:          time.Now,
+		processes:    process.NewProcFS(),
+		panes:        context.NewTmuxProvider(),
+	}
+}
+
+// Emission describes a single emit of a beacon.
+type Emission struct {
+	Message string
+	// Status defaults to DefaultStatus when empty.
+	Status Status
+	// Force accepts status transitions that are not allowed by the transition table.
+	Force bool
+	// ExpiresAt hides and garbage-collects the beacon after this time. Zero means never.
+	ExpiresAt time.Time
+	// Owner is the agent process; the beacon is reaped once it exits. Zero means none.
+	Owner process.Process
+	// Labels are merged into the labels of the beacon, overriding existing keys.
+	Labels Labels
+}
+
+// apply updates state with the emission, validating the status transition.
+func (e Emission) apply(state *State) error {
+	status := e.Status
+	if status == "" {
+		status = DefaultStatus
+	}
+	if state.EmitCount > 0 && !e.Force && !state.Status.CanTransitionTo(status) {
+		return fmt.Errorf("%w for %s: %s -> %s", ErrInvalidTransition, state.ID, state.Status, status)
+	}
+	state.Message = e.Message
+	state.Status = status
+	state.ExpiresAt = e.ExpiresAt
+	state.Owner = e.Owner
+	if len(e.Labels) > 0 {
+		if state.Labels == nil {
+			state.Labels = Labels{}
+		}
+		maps.Copy(state.Labels, e.Labels)
 	}
+	return nil
 }
 
 // Emit creates or updates a beacon state file for the given ID.
-func (b *Beacon) Emit(id string, message string) error {
-	return b.store.Write(id, message)
+func (b *Beacon) Emit(id string, e Emission) error {
+	event, err := b.update(id, e)
+	if err != nil {
+		return err
+	}
+	b.indicate(id, e, nil)
+	b.notify(event, nil)
+	return nil
 }
 
 // EmitWithContext creates or updates a beacon state file and context file for the given ID.
-func (b *Beacon) EmitWithContext(id string, message string, ctx context.Context) error {
-	if err := b.store.Write(id, message); err != nil {
+func (b *Beacon) EmitWithContext(id string, e Emission, ctx context.Context) error {
+	event, err := b.update(id, e)
+	if err != nil {
 		return err
 	}
 	if b.contextStore != nil && ctx != nil {
-		return b.contextStore.Write(id, ctx)
+		if err := b.contextStore.Write(id, ctx); err != nil {
+			return err
+		}
 	}
+	b.indicate(id, e, ctx)
+	b.notify(event, ctx)
 	return nil
 }
 
 // Silence removes the beacon state file and context file for the given ID.
+// Any indicator shown for the beacon is cleared and notifiers are told
+// about beacons that existed.
 func (b *Beacon) Silence(id string) error {
+	indicator := b.storedIndicator(id)
+	var last State
+	var known bool
+	var ctx context.Context
+	if len(b.notifiers) > 0 {
+		last, known = b.lastState(id)
+		ctx = b.storedContext(id)
+	}
 	if err := b.store.Delete(id); err != nil {
 		return err
 	}
 	if b.contextStore != nil {
-		return b.contextStore.Delete(id)
+		if err := b.contextStore.Delete(id); err != nil {
+			return err
+		}
+	}
+	if indicator != nil {
+		indicator.ClearIndication(b.indicators.executor, b.indicators.opts)
+	}
+	if known {
+		b.notify(Event{Type: EventRemoved, ID: id, State: last}, ctx)
 	}
 	return nil
 }
 
-// List displays all active beacon states to the output writer.
-func (b *Beacon) List() error {
+// List returns all active beacon states sorted by ID.
+// Expired beacons are hidden until they are garbage-collected.
+func (b *Beacon) List() ([]State, error) {
 	states, err := b.store.List()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	now := b.now()
+	active := states[:0]
 	for _, state := range states {
-		fmt.Fprintf(b.out, "%s\t%s\n", state.ID, state.Message)
+		if !state.Expired(now) {
+			active = append(active, state)
+		}
 	}
-	return nil
+	sort.Slice(active, func(i, j int) bool { return active[i].ID < active[j].ID })
+	return active, nil
 }
@@ -1,17 +1,18 @@
 package beacon
 
 import (
-	"bytes"
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/monochromegane/beacon/internal/context"
+	"github.com/monochromegane/beacon/internal/process"
 )
 
 // mockStore is a mock implementation of Store for testing.
 type mockStore struct {
-	states   map[string]string
+	states   map[string]State
 	writeErr error
 	delErr   error
 	listErr  error
@@ -19,16 +20,24 @@ type mockStore struct {
 
 func newMockStore() *mockStore {
 	return &mockStore{
-		states: make(map[string]string),
+		states: make(map[string]State),
 	}
 }
 
-func (m *mockStore) Write(id string, message string) error {
+func (m *mockStore) Update(id string, fn func(state *State) error) (State, error) {
 	if m.writeErr != nil {
-		return m.writeErr
+		return State{}, m.writeErr
 	}
-	m.states[id] = message
-	return nil
+	state, ok := m.states[id]
+	if !ok {
+		state = State{ID: id}
+	}
+	if err := fn(&state); err != nil {
+		return State{}, err
+	}
+	state.EmitCount++
+	m.states[id] = state
+	return state, nil
 }
 
 func (m *mockStore) Delete(id string) error {
@@ -44,8 +53,8 @@ func (m *mockStore) List() ([]State, error) {
 		return nil, m.listErr
 	}
 	var states []State
-	for id, msg := range m.states {
-		states = append(states, State{ID: id, Message: msg})
+	for _, state := range m.states {
+		states = append(states, state)
 	}
 	return states, nil
 }
@@ -89,24 +98,66 @@ func (m *mockContextStore) Read(id string) ([]byte, error) {
 
 func TestBeacon_Emit(t *testing.T) {
 	store := newMockStore()
-	b := New(store, nil)
+	b := New(store)
 
-	err := b.Emit("test123", "test message")
+	err := b.Emit("test123", Emission{Message: "test message"})
 	if err != nil {
 		t.Fatalf("Emit() error = %v", err)
 	}
 
-	if store.states["test123"] != "test message" {
-		t.Errorf("Emit() message = %q, want %q", store.states["test123"], "test message")
+	if store.states["test123"].Message != "test message" {
+		t.Errorf("Emit() message = %q, want %q", store.states["test123"].Message, "test message")
+	}
+	if store.states["test123"].Status != DefaultStatus {
+		t.Errorf("Emit() status = %q, want %q", store.states["test123"].Status, DefaultStatus)
+	}
+}
+
+func TestBeacon_Emit_Status(t *testing.T) {
+	store := newMockStore()
+	b := New(store)
+
+	if err := b.Emit("test123", Emission{Message: "working", Status: StatusRunning}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	if err := b.Emit("test123", Emission{Message: "finished", Status: StatusDone}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+
+	state := store.states["test123"]
+	if state.Status != StatusDone || state.Message != "finished" {
+		t.Errorf("Emit() state = %+v, want done/finished", state)
+	}
+}
+
+func TestBeacon_Emit_InvalidTransition(t *testing.T) {
+	store := newMockStore()
+	b := New(store)
+
+	b.Emit("test123", Emission{Message: "finished", Status: StatusDone})
+	err := b.Emit("test123", Emission{Message: "oops", Status: StatusFailed})
+	if !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("Emit() error = %v, want ErrInvalidTransition", err)
+	}
+	if state := store.states["test123"]; state.Status != StatusDone || state.Message != "finished" {
+		t.Errorf("Emit() state = %+v, want unchanged", state)
+	}
+
+	err = b.Emit("test123", Emission{Message: "oops", Status: StatusFailed, Force: true})
+	if err != nil {
+		t.Fatalf("Emit() with Force error = %v", err)
+	}
+	if state := store.states["test123"]; state.Status != StatusFailed {
+		t.Errorf("Emit() with Force status = %q, want %q", state.Status, StatusFailed)
 	}
 }
 
 func TestBeacon_Emit_Error(t *testing.T) {
 	store := newMockStore()
 	store.writeErr = errors.New("write error")
-	b := New(store, nil)
+	b := New(store)
 
-	err := b.Emit("test123", "test message")
+	err := b.Emit("test123", Emission{Message: "test message"})
 	if err == nil {
 		t.Error("Emit() expected error, got nil")
 	}
@@ -114,8 +165,8 @@ func TestBeacon_Emit_Error(t *testing.T) {
 
 func TestBeacon_Silence(t *testing.T) {
 	store := newMockStore()
-	store.states["test123"] = "test message"
-	b := New(store, nil)
+	store.states["test123"] = State{ID: "test123", Message: "test message"}
+	b := New(store)
 
 	err := b.Silence("test123")
 	if err != nil {
@@ -130,7 +181,7 @@ func TestBeacon_Silence(t *testing.T) {
 func TestBeacon_Silence_Error(t *testing.T) {
 	store := newMockStore()
 	store.delErr = errors.New("delete error")
-	b := New(store, nil)
+	b := New(store)
 
 	err := b.Silence("test123")
 	if err == nil {
@@ -140,44 +191,91 @@ func TestBeacon_Silence_Error(t *testing.T) {
 
 func TestBeacon_List(t *testing.T) {
 	store := newMockStore()
-	store.states["test123"] = "message 1"
-	var buf bytes.Buffer
-	b := New(store, &buf)
+	store.states["test456"] = State{ID: "test456", Message: "message 2", Status: StatusRunning}
+	store.states["test123"] = State{ID: "test123", Message: "message 1", Status: StatusWaiting}
+	b := New(store)
+
+	states, err := b.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(states) != 2 {
+		t.Fatalf("List() len = %d, want 2", len(states))
+	}
+	if states[0].ID != "test123" || states[1].ID != "test456" {
+		t.Errorf("List() order = %s, %s, want test123, test456", states[0].ID, states[1].ID)
+	}
+	if states[0].Message != "message 1" || states[0].Status != StatusWaiting {
+		t.Errorf("List() state[0] = %+v", states[0])
+	}
+}
+
+func TestBeacon_List_HidesExpired(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := newMockStore()
+	store.states["expired"] = State{ID: "expired", Message: "old", Status: StatusWaiting, ExpiresAt: now}
+	store.states["alive"] = State{ID: "alive", Message: "new", Status: StatusWaiting, ExpiresAt: now.Add(time.Second)}
+	b := New(store)
+	b.now = func() time.Time { return now }
 
-	err := b.List()
+	states, err := b.List()
 	if err != nil {
 		t.Fatalf("List() error = %v", err)
 	}
 
-	output := buf.String()
-	expected := "test123\tmessage 1\n"
-	if output != expected {
-		t.Errorf("List() output = %q, want %q", output, expected)
+	if len(states) != 1 || states[0].ID != "alive" {
+		t.Errorf("List() = %+v, want only alive", states)
+	}
+}
+
+func TestBeacon_Emit_ExpiresAt(t *testing.T) {
+	store := newMockStore()
+	b := New(store)
+	expiresAt := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	b.Emit("test123", Emission{Message: "first", ExpiresAt: expiresAt})
+	if !store.states["test123"].ExpiresAt.Equal(expiresAt) {
+		t.Errorf("Emit() expires_at = %v, want %v", store.states["test123"].ExpiresAt, expiresAt)
+	}
+
+	b.Emit("test123", Emission{Message: "second"})
+	if !store.states["test123"].ExpiresAt.IsZero() {
+		t.Errorf("Emit() without expiry kept expires_at = %v", store.states["test123"].ExpiresAt)
+	}
+}
+
+func TestBeacon_Emit_Owner(t *testing.T) {
+	store := newMockStore()
+	b := New(store)
+	owner := process.Process{PID: 1234, StartTime: 98765}
+
+	b.Emit("test123", Emission{Message: "test message", Owner: owner})
+	if store.states["test123"].Owner != owner {
+		t.Errorf("Emit() owner = %+v, want %+v", store.states["test123"].Owner, owner)
 	}
 }
 
 func TestBeacon_List_Empty(t *testing.T) {
 	store := newMockStore()
-	var buf bytes.Buffer
-	b := New(store, &buf)
+	b := New(store)
 
-	err := b.List()
+	states, err := b.List()
 	if err != nil {
 		t.Fatalf("List() error = %v", err)
 	}
 
-	if buf.String() != "" {
-		t.Errorf("List() output = %q, want empty", buf.String())
+	if len(states) != 0 {
+		t.Errorf("List() len = %d, want 0", len(states))
 	}
 }
 
 func TestBeacon_List_Error(t *testing.T) {
 	store := newMockStore()
 	store.listErr = errors.New("list error")
-	var buf bytes.Buffer
-	b := New(store, &buf)
+	b := New(store)
 
-	err := b.List()
+	_, err := b.List()
 	if err == nil {
 		t.Error("List() expected error, got nil")
 	}
@@ -201,20 +299,20 @@ func (m *mockContext) ToJSON() ([]byte, error) {
 func TestBeacon_EmitWithContext(t *testing.T) {
 	store := newMockStore()
 	contextStore := newMockContextStore()
-	b := NewWithContextStore(store, contextStore, nil)
+	b := NewWithContextStore(store, contextStore)
 
 	ctx := &mockContext{
 		contextType: "tmux",
 		json:        []byte(`{"session_name":"main"}`),
 	}
 
-	err := b.EmitWithContext("test123", "test message", ctx)
+	err := b.EmitWithContext("test123", Emission{Message: "test message"}, ctx)
 	if err != nil {
 		t.Fatalf("EmitWithContext() error = %v", err)
 	}
 
-	if store.states["test123"] != "test message" {
-		t.Errorf("EmitWithContext() message = %q, want %q", store.states["test123"], "test message")
+	if store.states["test123"].Message != "test message" {
+		t.Errorf("EmitWithContext() message = %q, want %q", store.states["test123"].Message, "test message")
 	}
 
 	if contextStore.contexts["test123"] == nil {
@@ -226,11 +324,11 @@ func TestBeacon_EmitWithContext_StoreError(t *testing.T) {
 	store := newMockStore()
 	store.writeErr = errors.New("write error")
 	contextStore := newMockContextStore()
-	b := NewWithContextStore(store, contextStore, nil)
+	b := NewWithContextStore(store, contextStore)
 
 	ctx := &mockContext{contextType: "tmux", json: []byte(`{}`)}
 
-	err := b.EmitWithContext("test123", "test message", ctx)
+	err := b.EmitWithContext("test123", Emission{Message: "test message"}, ctx)
 	if err == nil {
 		t.Error("EmitWithContext() expected error, got nil")
 	}
@@ -240,11 +338,11 @@ func TestBeacon_EmitWithContext_ContextStoreError(t *testing.T) {
 	store := newMockStore()
 	contextStore := newMockContextStore()
 	contextStore.writeErr = errors.New("context write error")
-	b := NewWithContextStore(store, contextStore, nil)
+	b := NewWithContextStore(store, contextStore)
 
 	ctx := &mockContext{contextType: "tmux", json: []byte(`{}`)}
 
-	err := b.EmitWithContext("test123", "test message", ctx)
+	err := b.EmitWithContext("test123", Emission{Message: "test message"}, ctx)
 	if err == nil {
 		t.Error("EmitWithContext() expected error, got nil")
 	}
@@ -252,26 +350,26 @@ func TestBeacon_EmitWithContext_ContextStoreError(t *testing.T) {
 
 func TestBeacon_EmitWithContext_NilContextStore(t *testing.T) {
 	store := newMockStore()
-	b := New(store, nil)
+	b := New(store)
 
 	ctx := &mockContext{contextType: "tmux", json: []byte(`{}`)}
 
-	err := b.EmitWithContext("test123", "test message", ctx)
+	err := b.EmitWithContext("test123", Emission{Message: "test message"}, ctx)
 	if err != nil {
 		t.Fatalf("EmitWithContext() error = %v", err)
 	}
 
-	if store.states["test123"] != "test message" {
-		t.Errorf("EmitWithContext() message = %q, want %q", store.states["test123"], "test message")
+	if store.states["test123"].Message != "test message" {
+		t.Errorf("EmitWithContext() message = %q, want %q", store.states["test123"].Message, "test message")
 	}
 }
 
 func TestBeacon_Silence_WithContextStore(t *testing.T) {
 	store := newMockStore()
-	store.states["test123"] = "test message"
+	store.states["test123"] = State{ID: "test123", Message: "test message"}
 	contextStore := newMockContextStore()
 	contextStore.contexts["test123"] = &mockContext{contextType: "tmux"}
-	b := NewWithContextStore(store, contextStore, nil)
+	b := NewWithContextStore(store, contextStore)
 
 	err := b.Silence("test123")
 	if err != nil {
@@ -289,10 +387,10 @@ func TestBeacon_Silence_WithContextStore(t *testing.T) {
 
 func TestBeacon_Silence_ContextStoreError(t *testing.T) {
 	store := newMockStore()
-	store.states["test123"] = "test message"
+	store.states["test123"] = State{ID: "test123", Message: "test message"}
 	contextStore := newMockContextStore()
 	contextStore.delErr = errors.New("context delete error")
-	b := NewWithContextStore(store, contextStore, nil)
+	b := NewWithContextStore(store, contextStore)
 
 	err := b.Silence("test123")
 	if err == nil {
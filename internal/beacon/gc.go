@@ -0,0 +1,106 @@
+package beacon
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// GCOptions controls which beacons GC removes.
+// Expired beacons are always removed.
+type GCOptions struct {
+	// OlderThan also removes beacons not updated within this duration. Zero disables it.
+	OlderThan time.Duration
+	// Dead also removes beacons whose owner process is no longer running.
+	Dead bool
+	// Tmux also removes beacons whose recorded tmux pane no longer exists.
+	Tmux bool
+	// DryRun reports what would be removed without deleting anything.
+	DryRun bool
+}
+
+// Removal describes a beacon removed (or to be removed) by GC.
+type Removal struct {
+	ID     string
+	Reason string
+}
+
+// GC removes the state and context of stale beacons and reports them.
+func (b *Beacon) GC(opts GCOptions) ([]Removal, error) {
+	states, err := b.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var panes map[string]bool
+	if opts.Tmux {
+		panes, err = b.panes.ListPanes()
+		if err != nil {
+			return nil, fmt.Errorf("listing tmux panes: %w", err)
+		}
+	}
+
+	now := b.now()
+	var removals []Removal
+	for _, state := range states {
+		var reason string
+		switch {
+		case state.Expired(now):
+			reason = "expired"
+		case opts.OlderThan > 0 && now.Sub(state.UpdatedAt) > opts.OlderThan:
+			reason = fmt.Sprintf("not updated for %s", opts.OlderThan)
+		case opts.Dead && b.ownerExited(state):
+			reason = fmt.Sprintf("process %d exited", state.Owner.PID)
+		case opts.Tmux && b.paneGone(state.ID, panes):
+			reason = "tmux pane is gone"
+		default:
+			continue
+		}
+		removals = append(removals, Removal{ID: state.ID, Reason: reason})
+	}
+	sort.Slice(removals, func(i, j int) bool { return removals[i].ID < removals[j].ID })
+
+	if opts.DryRun {
+		return removals, nil
+	}
+	for i, removal := range removals {
+		if err := b.Silence(removal.ID); err != nil {
+			return removals[:i], err
+		}
+	}
+	return removals, nil
+}
+
+// ReconcileTmux silences every beacon bound to a tmux pane that no longer
+// exists and reports them. With dryRun, nothing is removed.
+func (b *Beacon) ReconcileTmux(dryRun bool) ([]Removal, error) {
+	return b.GC(GCOptions{Tmux: true, DryRun: dryRun})
+}
+
+// paneGone reports whether the beacon has a tmux context whose pane is not in panes.
+func (b *Beacon) paneGone(id string, panes map[string]bool) bool {
+	if b.contextStore == nil {
+		return false
+	}
+	data, err := b.contextStore.Read(id)
+	if err != nil {
+		return false
+	}
+	ctx, err := context.ParseTmuxContext(data)
+	if err != nil {
+		return false
+	}
+	return !panes[ctx.PaneID]
+}
+
+// ownerExited reports whether the owner process of state is known to be gone.
+// Beacons without an owner, or whose liveness cannot be determined, are kept.
+func (b *Beacon) ownerExited(state State) bool {
+	if state.Owner.PID <= 0 {
+		return false
+	}
+	alive, err := b.processes.Alive(state.Owner)
+	return err == nil && !alive
+}
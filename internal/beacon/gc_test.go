@@ -0,0 +1,266 @@
+package beacon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/context"
+	"github.com/monochromegane/beacon/internal/process"
+)
+
+func newGCTestBeacon(now time.Time) (*Beacon, *mockStore, *mockContextStore) {
+	store := newMockStore()
+	contextStore := newMockContextStore()
+	store.states["expired"] = State{ID: "expired", UpdatedAt: now.Add(-time.Minute), ExpiresAt: now.Add(-time.Second)}
+	store.states["stale"] = State{ID: "stale", UpdatedAt: now.Add(-2 * time.Hour)}
+	store.states["fresh"] = State{ID: "fresh", UpdatedAt: now.Add(-time.Minute), ExpiresAt: now.Add(time.Hour)}
+	contextStore.contexts["expired"] = &mockContext{contextType: "tmux"}
+	b := NewWithContextStore(store, contextStore)
+	b.now = func() time.Time { return now }
+	return b, store, contextStore
+}
+
+func TestBeacon_GC_Expired(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	b, store, contextStore := newGCTestBeacon(now)
+
+	removals, err := b.GC(GCOptions{})
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+
+	if len(removals) != 1 || removals[0].ID != "expired" || removals[0].Reason != "expired" {
+		t.Errorf("GC() removals = %+v, want [expired]", removals)
+	}
+	if _, ok := store.states["expired"]; ok {
+		t.Error("GC() expired state still exists")
+	}
+	if _, ok := contextStore.contexts["expired"]; ok {
+		t.Error("GC() expired context still exists")
+	}
+	if len(store.states) != 2 {
+		t.Errorf("GC() remaining states = %d, want 2", len(store.states))
+	}
+}
+
+func TestBeacon_GC_OlderThan(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	b, store, _ := newGCTestBeacon(now)
+
+	removals, err := b.GC(GCOptions{OlderThan: time.Hour})
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+
+	if len(removals) != 2 || removals[0].ID != "expired" || removals[1].ID != "stale" {
+		t.Errorf("GC() removals = %+v, want [expired stale]", removals)
+	}
+	if _, ok := store.states["fresh"]; !ok || len(store.states) != 1 {
+		t.Errorf("GC() remaining states = %v, want only fresh", store.states)
+	}
+}
+
+func TestBeacon_GC_DryRun(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	b, store, contextStore := newGCTestBeacon(now)
+
+	removals, err := b.GC(GCOptions{OlderThan: time.Hour, DryRun: true})
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+
+	if len(removals) != 2 {
+		t.Errorf("GC() removals = %+v, want 2", removals)
+	}
+	if len(store.states) != 3 || len(contextStore.contexts) != 1 {
+		t.Error("GC() with DryRun removed files")
+	}
+}
+
+func TestBeacon_GC_Error(t *testing.T) {
+	store := newMockStore()
+	store.listErr = errors.New("list error")
+	b := New(store)
+
+	if _, err := b.GC(GCOptions{}); err == nil {
+		t.Error("GC() expected error, got nil")
+	}
+}
+
+// mockProcessChecker reports the PIDs in alive as running.
+type mockProcessChecker struct {
+	alive map[int]bool
+	err   error
+}
+
+func (m *mockProcessChecker) Alive(proc process.Process) (bool, error) {
+	return m.alive[proc.PID], m.err
+}
+
+func TestBeacon_GC_Dead(t *testing.T) {
+	store := newMockStore()
+	store.states["running"] = State{ID: "running", Owner: process.Process{PID: 100, StartTime: 1}}
+	store.states["killed"] = State{ID: "killed", Owner: process.Process{PID: 200, StartTime: 1}}
+	store.states["unowned"] = State{ID: "unowned"}
+	b := New(store)
+	b.processes = &mockProcessChecker{alive: map[int]bool{100: true}}
+
+	removals, err := b.GC(GCOptions{})
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+	if len(removals) != 0 {
+		t.Errorf("GC() without Dead removals = %+v, want none", removals)
+	}
+
+	removals, err = b.GC(GCOptions{Dead: true})
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+	if len(removals) != 1 || removals[0].ID != "killed" || removals[0].Reason != "process 200 exited" {
+		t.Errorf("GC() removals = %+v, want [killed]", removals)
+	}
+	if len(store.states) != 2 {
+		t.Errorf("GC() remaining states = %d, want 2", len(store.states))
+	}
+}
+
+func TestBeacon_GC_Dead_Unsupported(t *testing.T) {
+	store := newMockStore()
+	store.states["owned"] = State{ID: "owned", Owner: process.Process{PID: 100}}
+	b := New(store)
+	b.processes = &mockProcessChecker{err: process.ErrUnsupported}
+
+	removals, err := b.GC(GCOptions{Dead: true})
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+	if len(removals) != 0 {
+		t.Errorf("GC() removals = %+v, want none when liveness is unknown", removals)
+	}
+}
+
+func TestBeacon_GC_Dead_ProcFS(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "100"), 0755)
+	os.WriteFile(filepath.Join(root, "100", "stat"), []byte("100 (agent) S 1 1 1 0 -1 0 0 0 0 0 0 0 0 0 20 0 1 0 4242 0 0\n"), 0644)
+
+	store := newMockStore()
+	store.states["alive"] = State{ID: "alive", Owner: process.Process{PID: 100, StartTime: 4242}}
+	store.states["reused"] = State{ID: "reused", Owner: process.Process{PID: 100, StartTime: 1111}}
+	store.states["gone"] = State{ID: "gone", Owner: process.Process{PID: 200, StartTime: 4242}}
+	b := New(store)
+	b.processes = process.NewProcFSWithRoot(root)
+
+	removals, err := b.GC(GCOptions{Dead: true})
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+	if len(removals) != 2 || removals[0].ID != "gone" || removals[1].ID != "reused" {
+		t.Errorf("GC() removals = %+v, want [gone reused]", removals)
+	}
+}
+
+// mockExecutor implements context.CommandExecutor for testing.
+type mockExecutor struct {
+	output []byte
+	err    error
+	calls  []string
+}
+
+func (m *mockExecutor) Execute(name string, args ...string) ([]byte, error) {
+	m.calls = append(m.calls, strings.Join(append([]string{name}, args...), " "))
+	return m.output, m.err
+}
+
+func newTmuxGCTestBeacon(executor *mockExecutor) (*Beacon, *mockStore, *mockContextStore) {
+	store := newMockStore()
+	contextStore := newMockContextStore()
+	store.states["live"] = State{ID: "live"}
+	store.states["closed"] = State{ID: "closed"}
+	store.states["plain"] = State{ID: "plain"}
+	contextStore.contexts["live"] = &context.TmuxContext{SessionName: "main", PaneID: "%1"}
+	contextStore.contexts["closed"] = &context.TmuxContext{SessionName: "main", PaneID: "%7"}
+	b := NewWithContextStore(store, contextStore)
+	b.panes = context.NewTmuxProviderWithExecutor(executor)
+	return b, store, contextStore
+}
+
+func TestBeacon_ReconcileTmux(t *testing.T) {
+	b, store, contextStore := newTmuxGCTestBeacon(&mockExecutor{output: []byte("%0\n%1\n")})
+
+	removals, err := b.ReconcileTmux(false)
+	if err != nil {
+		t.Fatalf("ReconcileTmux() error = %v", err)
+	}
+
+	if len(removals) != 1 || removals[0].ID != "closed" || removals[0].Reason != "tmux pane is gone" {
+		t.Errorf("ReconcileTmux() removals = %+v, want [closed]", removals)
+	}
+	if _, ok := store.states["closed"]; ok {
+		t.Error("ReconcileTmux() state of closed pane still exists")
+	}
+	if _, ok := contextStore.contexts["closed"]; ok {
+		t.Error("ReconcileTmux() context of closed pane still exists")
+	}
+	if len(store.states) != 2 {
+		t.Errorf("ReconcileTmux() remaining states = %d, want 2", len(store.states))
+	}
+}
+
+func TestBeacon_ReconcileTmux_DryRun(t *testing.T) {
+	b, store, _ := newTmuxGCTestBeacon(&mockExecutor{output: []byte("%0\n%1\n")})
+
+	removals, err := b.ReconcileTmux(true)
+	if err != nil {
+		t.Fatalf("ReconcileTmux() error = %v", err)
+	}
+	if len(removals) != 1 || len(store.states) != 3 {
+		t.Errorf("ReconcileTmux() dry run removals = %+v, states = %d", removals, len(store.states))
+	}
+}
+
+func TestBeacon_ReconcileTmux_ListError(t *testing.T) {
+	b, store, _ := newTmuxGCTestBeacon(&mockExecutor{err: errors.New("exit status 1: open terminal failed: not a terminal")})
+
+	if _, err := b.ReconcileTmux(false); err == nil {
+		t.Error("ReconcileTmux() expected error, got nil")
+	}
+	if len(store.states) != 3 {
+		t.Error("ReconcileTmux() removed beacons although panes could not be listed")
+	}
+}
+
+func TestBeacon_GC_TmuxNoServer(t *testing.T) {
+	executor := &mockExecutor{err: errors.New("exit status 1: no server running on /tmp/tmux-1000/default")}
+	b, store, _ := newTmuxGCTestBeacon(executor)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+	store.states["expired"] = State{ID: "expired", ExpiresAt: now.Add(-time.Minute)}
+
+	removals, err := b.GC(GCOptions{Tmux: true})
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+	// Without a server every recorded pane is gone.
+	want := []Removal{
+		{ID: "closed", Reason: "tmux pane is gone"},
+		{ID: "expired", Reason: "expired"},
+		{ID: "live", Reason: "tmux pane is gone"},
+	}
+	if len(removals) != len(want) {
+		t.Fatalf("GC() removals = %+v, want %+v", removals, want)
+	}
+	for i := range want {
+		if removals[i] != want[i] {
+			t.Errorf("GC() removal %d = %+v, want %+v", i, removals[i], want[i])
+		}
+	}
+	if len(store.states) != 1 {
+		t.Errorf("GC() remaining states = %d, want 1", len(store.states))
+	}
+}
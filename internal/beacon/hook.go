@@ -0,0 +1,105 @@
+package beacon
+
+import (
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// DefaultHookTimeout bounds a hook run when Hooks.Timeout is zero.
+const DefaultHookTimeout = 10 * time.Second
+
+// Hooks are executables run on beacon lifecycle events. Each receives the
+// event as a Payload in JSON on stdin and as BEACON_* environment variables:
+// BEACON_EVENT, BEACON_ID, BEACON_STATUS, BEACON_MESSAGE, BEACON_LABELS,
+// BEACON_LOCATION and BEACON_TIMESTAMP. Empty paths are skipped.
+type Hooks struct {
+	// OnEmit runs when a beacon is emitted that was not active.
+	OnEmit string
+	// OnUpdate runs when an active beacon is emitted again.
+	OnUpdate string
+	// OnSilence runs when a beacon is silenced, including by GC.
+	OnSilence string
+	// Timeout bounds each run; the hook is killed once it passes.
+	Timeout time.Duration
+}
+
+// SetHooks runs hooks on emits and silences. Like notifiers, hooks are best
+// effort: a failing or timed-out hook is reported to the handler set with
+// OnNotifyError and never fails the emit or silence.
+func (b *Beacon) SetHooks(hooks Hooks) {
+	if hooks.Timeout <= 0 {
+		hooks.Timeout = DefaultHookTimeout
+	}
+	b.AddNotifier(&hookRunner{hooks: hooks, now: b.now})
+}
+
+// hookRunner is the Notifier running Hooks.
+type hookRunner struct {
+	hooks Hooks
+	now   func() time.Time
+}
+
+func (r *hookRunner) Notify(event Event, ctx context.Context) error {
+	var name, path string
+	switch event.Type {
+	case EventAdded:
+		name, path = "on_emit", r.hooks.OnEmit
+	case EventUpdated:
+		name, path = "on_update", r.hooks.OnUpdate
+	case EventRemoved:
+		name, path = "on_silence", r.hooks.OnSilence
+	}
+	if path == "" {
+		return nil
+	}
+
+	payload := NewPayload(event, ctx, r.now())
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	runCtx, cancel := stdcontext.WithTimeout(stdcontext.Background(), r.hooks.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(runCtx, path)
+	cmd.Stdin = bytes.NewReader(append(input, '\n'))
+	cmd.Env = append(os.Environ(), hookEnv(payload)...)
+	// Children that keep the output open must not outlive the time limit.
+	cmd.WaitDelay = time.Second
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Run()
+	if runCtx.Err() == stdcontext.DeadlineExceeded {
+		return fmt.Errorf("hook %s: %s timed out after %s", name, path, r.hooks.Timeout)
+	}
+	if err != nil {
+		if out := strings.TrimSpace(output.String()); out != "" {
+			return fmt.Errorf("hook %s: %s: %w: %s", name, path, err, out)
+		}
+		return fmt.Errorf("hook %s: %s: %w", name, path, err)
+	}
+	return nil
+}
+
+// hookEnv returns the BEACON_* variables describing payload.
+func hookEnv(p Payload) []string {
+	return []string{
+		"BEACON_EVENT=" + string(p.Event),
+		"BEACON_ID=" + p.ID,
+		"BEACON_STATUS=" + string(p.Status),
+		"BEACON_MESSAGE=" + p.Message,
+		"BEACON_LABELS=" + p.Labels.String(),
+		"BEACON_LOCATION=" + p.Location,
+		"BEACON_TIMESTAMP=" + strconv.FormatInt(p.Timestamp.Unix(), 10),
+	}
+}
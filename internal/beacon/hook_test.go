@@ -0,0 +1,145 @@
+package beacon
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// writeHook writes a shell script running body to dir and returns its path.
+func writeHook(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are shell scripts in these tests")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBeacon_Hooks(t *testing.T) {
+	dir := t.TempDir()
+	// Each hook records its stdin and BEACON_* variables next to itself.
+	record := `cat > "$0.stdin"; env | grep '^BEACON_' | sort > "$0.env"`
+	b := NewWithContextStore(newMockStore(), newMockContextStore())
+	b.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	b.SetHooks(Hooks{
+		OnEmit:    writeHook(t, dir, "emit", record),
+		OnUpdate:  writeHook(t, dir, "update", record),
+		OnSilence: writeHook(t, dir, "silence", record),
+	})
+	var reported []error
+	b.OnNotifyError(func(err error) { reported = append(reported, err) })
+	ctx := &context.TmuxContext{SessionName: "main", WindowIndex: 1, PaneIndex: 0, PaneID: "%3"}
+
+	if err := b.EmitWithContext("api", Emission{Message: "approve?", Labels: Labels{"project": "web"}}, ctx); err != nil {
+		t.Fatalf("EmitWithContext() error = %v", err)
+	}
+	if err := b.Emit("api", Emission{Message: "stuck", Status: StatusBlocked}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	if err := b.Silence("api"); err != nil {
+		t.Fatalf("Silence() error = %v", err)
+	}
+	if len(reported) > 0 {
+		t.Fatalf("hook errors = %v", reported)
+	}
+
+	tests := []struct {
+		hook    string
+		event   EventType
+		status  Status
+		message string
+	}{
+		{"emit", EventAdded, StatusWaiting, "approve?"},
+		{"update", EventUpdated, StatusBlocked, "stuck"},
+		{"silence", EventRemoved, StatusBlocked, "stuck"},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join(dir, tt.hook+".stdin"))
+		if err != nil {
+			t.Fatalf("hook %s did not run: %v", tt.hook, err)
+		}
+		var payload Payload
+		if err := json.Unmarshal(data, &payload); err != nil {
+			t.Fatalf("hook %s stdin %q is not JSON: %v", tt.hook, data, err)
+		}
+		if payload.Event != tt.event || payload.ID != "api" || payload.Status != tt.status ||
+			payload.Message != tt.message || payload.Location != "main:1.0" {
+			t.Errorf("hook %s payload = %+v", tt.hook, payload)
+		}
+
+		env, err := os.ReadFile(filepath.Join(dir, tt.hook+".env"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Join([]string{
+			"BEACON_EVENT=" + string(tt.event),
+			"BEACON_ID=api",
+			"BEACON_LABELS=project=web",
+			"BEACON_LOCATION=main:1.0",
+			"BEACON_MESSAGE=" + tt.message,
+			"BEACON_STATUS=" + string(tt.status),
+			"BEACON_TIMESTAMP=1767323045",
+		}, "\n") + "\n"
+		if string(env) != want {
+			t.Errorf("hook %s env =\n%s\nwant\n%s", tt.hook, env, want)
+		}
+	}
+}
+
+func TestBeacon_Hooks_Failures(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name  string
+		hooks Hooks
+		want  string
+	}{
+		{
+			name:  "exit status",
+			hooks: Hooks{OnEmit: writeHook(t, dir, "fail", "echo 'no route to host' >&2; exit 3")},
+			want:  "hook on_emit: " + filepath.Join(dir, "fail") + ": exit status 3: no route to host",
+		},
+		{
+			name:  "missing",
+			hooks: Hooks{OnEmit: filepath.Join(dir, "missing")},
+			want:  "hook on_emit: " + filepath.Join(dir, "missing") + ": ",
+		},
+		{
+			name:  "timeout",
+			hooks: Hooks{OnEmit: writeHook(t, dir, "slow", "exec sleep 10"), Timeout: 50 * time.Millisecond},
+			want:  "hook on_emit: " + filepath.Join(dir, "slow") + " timed out after 50ms",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockStore()
+			b := New(store)
+			b.SetHooks(tt.hooks)
+			var reported []error
+			b.OnNotifyError(func(err error) { reported = append(reported, err) })
+
+			start := time.Now()
+			if err := b.Emit("api", Emission{Message: "m"}); err != nil {
+				t.Fatalf("Emit() error = %v, want nil despite the hook failing", err)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("Emit() took %v despite the time limit", elapsed)
+			}
+			if _, ok := store.states["api"]; !ok {
+				t.Error("beacon was not emitted")
+			}
+			if len(reported) != 1 || !strings.HasPrefix(reported[0].Error(), tt.want) {
+				t.Errorf("reported = %v, want one error starting with %q", reported, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package beacon
+
+import (
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// indicatorSettings holds how indicators are driven once enabled.
+type indicatorSettings struct {
+	executor context.CommandExecutor
+	opts     context.IndicatorOptions
+}
+
+// EnableIndicators makes emits and silences mark the terminal location of
+// beacons whose context is a context.Indicator, such as a tmux pane.
+// Indicators are best effort: failing to update one never fails an emit or silence.
+func (b *Beacon) EnableIndicators(executor context.CommandExecutor, opts context.IndicatorOptions) {
+	b.indicators = &indicatorSettings{executor: executor, opts: opts}
+}
+
+// indicate marks the location of the beacon. If ctx is nil, the stored context is used.
+func (b *Beacon) indicate(id string, e Emission, ctx context.Context) {
+	if b.indicators == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = b.storedContext(id)
+	}
+	indicator, ok := ctx.(context.Indicator)
+	if !ok {
+		return
+	}
+	status := e.Status
+	if status == "" {
+		status = DefaultStatus
+	}
+	indicator.Indicate(b.indicators.executor, b.indicators.opts, context.Indication{
+		ID:      id,
+		Status:  string(status),
+		Message: e.Message,
+	})
+}
+
+// storedIndicator returns the indicator of the stored context of id, or nil
+// if indicators are disabled or the context cannot indicate.
+func (b *Beacon) storedIndicator(id string) context.Indicator {
+	if b.indicators == nil {
+		return nil
+	}
+	indicator, _ := b.storedContext(id).(context.Indicator)
+	return indicator
+}
+
+// storedContext returns the decoded context of id, or nil if there is none.
+func (b *Beacon) storedContext(id string) context.Context {
+	if b.contextStore == nil {
+		return nil
+	}
+	data, err := b.contextStore.Read(id)
+	if err != nil {
+		return nil
+	}
+	ctx, err := context.Decode(data)
+	if err != nil {
+		return nil
+	}
+	return ctx
+}
@@ -0,0 +1,99 @@
+package beacon
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+func TestBeacon_Indicators(t *testing.T) {
+	store := newMockStore()
+	contextStore := newMockContextStore()
+	executor := &mockExecutor{}
+	b := NewWithContextStore(store, contextStore)
+	b.EnableIndicators(executor, context.IndicatorOptions{WindowStatusStyle: "bg=yellow"})
+	ctx := &context.TmuxContext{SessionName: "main", WindowIndex: 1, PaneID: "%3"}
+
+	if err := b.EmitWithContext("test123", Emission{Message: "m", Status: StatusRunning}, ctx); err != nil {
+		t.Fatalf("EmitWithContext() error = %v", err)
+	}
+	// A later emit without context updates the indicator of the stored context.
+	if err := b.Emit("test123", Emission{Message: "m"}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	if err := b.Silence("test123"); err != nil {
+		t.Fatalf("Silence() error = %v", err)
+	}
+
+	want := []string{
+		"tmux set-option -p -t %3 @beacon_status running",
+		"tmux set-option -w -t %3 @beacon_status running",
+		"tmux show-options -w -q -v -t %3 window-status-style",
+		"tmux set-option -w -t %3 window-status-style bg=yellow",
+		"tmux set-option -p -t %3 @beacon_status waiting-for-input",
+		"tmux set-option -w -t %3 @beacon_status waiting-for-input",
+		"tmux show-options -w -q -v -t %3 window-status-style",
+		"tmux set-option -w -t %3 window-status-style bg=yellow",
+		"tmux set-option -p -u -t %3 @beacon_status",
+		"tmux list-panes -t %3 -F #{pane_id}",
+		"tmux set-option -w -u -t %3 @beacon_status",
+		"tmux show-options -w -q -v -t %3 @beacon_saved_style",
+		"tmux set-option -w -u -t %3 window-status-style",
+	}
+	if got := strings.Join(executor.calls, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("executed\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestBeacon_Indicators_Disabled(t *testing.T) {
+	contextStore := newMockContextStore()
+	b := NewWithContextStore(newMockStore(), contextStore)
+	ctx := &context.TmuxContext{SessionName: "main", PaneID: "%3"}
+
+	if err := b.EmitWithContext("test123", Emission{Message: "m"}, ctx); err != nil {
+		t.Fatalf("EmitWithContext() error = %v", err)
+	}
+	if err := b.Silence("test123"); err != nil {
+		t.Fatalf("Silence() error = %v", err)
+	}
+}
+
+func TestBeacon_Indicators_BestEffort(t *testing.T) {
+	store := newMockStore()
+	contextStore := newMockContextStore()
+	executor := &mockExecutor{err: errors.New("no server running")}
+	b := NewWithContextStore(store, contextStore)
+	b.EnableIndicators(executor, context.IndicatorOptions{})
+	ctx := &context.TmuxContext{SessionName: "main", PaneID: "%3"}
+
+	if err := b.EmitWithContext("test123", Emission{Message: "m"}, ctx); err != nil {
+		t.Fatalf("EmitWithContext() error = %v, want nil despite indicator failure", err)
+	}
+	if err := b.Silence("test123"); err != nil {
+		t.Fatalf("Silence() error = %v, want nil despite indicator failure", err)
+	}
+	if len(executor.calls) == 0 {
+		t.Error("indicator was not attempted")
+	}
+	if _, ok := store.states["test123"]; ok {
+		t.Error("Silence did not delete the state")
+	}
+}
+
+func TestBeacon_Indicators_NoContext(t *testing.T) {
+	executor := &mockExecutor{}
+	b := NewWithContextStore(newMockStore(), newMockContextStore())
+	b.EnableIndicators(executor, context.IndicatorOptions{})
+
+	if err := b.Emit("test123", Emission{Message: "m"}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	if err := b.Silence("test123"); err != nil {
+		t.Fatalf("Silence() error = %v", err)
+	}
+	if len(executor.calls) != 0 {
+		t.Errorf("executed %v for a beacon without context", executor.calls)
+	}
+}
@@ -0,0 +1,37 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// ErrNoContext is returned when a beacon has no recorded context.
+var ErrNoContext = errors.New("no context recorded")
+
+// Jump brings the user to the terminal location recorded in the context of
+// the given beacon, using executor to drive the terminal multiplexer.
+func (b *Beacon) Jump(id string, executor context.CommandExecutor) error {
+	if b.contextStore == nil {
+		return fmt.Errorf("%w for %s", ErrNoContext, id)
+	}
+	data, err := b.contextStore.Read(id)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w for %s", ErrNoContext, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx, err := context.Decode(data)
+	if err != nil {
+		return err
+	}
+	jumper, ok := ctx.(context.Jumper)
+	if !ok {
+		return fmt.Errorf("%s context of %s does not support jumping", ctx.Type(), id)
+	}
+	return jumper.Jump(executor)
+}
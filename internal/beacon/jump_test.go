@@ -0,0 +1,52 @@
+package beacon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+func TestBeacon_Jump(t *testing.T) {
+	contextStore := newMockContextStore()
+	contextStore.contexts["test123"] = &context.TmuxContext{SessionName: "main", WindowIndex: 1, PaneID: "%3"}
+	b := NewWithContextStore(newMockStore(), contextStore)
+
+	if err := b.Jump("test123", &mockExecutor{}); err != nil {
+		t.Fatalf("Jump() error = %v", err)
+	}
+}
+
+func TestBeacon_Jump_TargetGone(t *testing.T) {
+	contextStore := newMockContextStore()
+	contextStore.contexts["test123"] = &context.TmuxContext{SessionName: "main", WindowIndex: 1, PaneID: "%3"}
+	b := NewWithContextStore(newMockStore(), contextStore)
+
+	err := b.Jump("test123", &mockExecutor{err: errors.New("can't find pane")})
+	if !errors.Is(err, context.ErrTargetGone) {
+		t.Errorf("Jump() error = %v, want ErrTargetGone", err)
+	}
+}
+
+func TestBeacon_Jump_NoContext(t *testing.T) {
+	b := NewWithContextStore(newMockStore(), newMockContextStore())
+
+	if err := b.Jump("test123", &mockExecutor{}); !errors.Is(err, ErrNoContext) {
+		t.Errorf("Jump() error = %v, want ErrNoContext", err)
+	}
+
+	b = New(newMockStore())
+	if err := b.Jump("test123", &mockExecutor{}); !errors.Is(err, ErrNoContext) {
+		t.Errorf("Jump() without context store error = %v, want ErrNoContext", err)
+	}
+}
+
+func TestBeacon_Jump_NotJumper(t *testing.T) {
+	contextStore := newMockContextStore()
+	contextStore.contexts["test123"] = &mockContext{contextType: "other", json: []byte(`{"foo":"bar"}`)}
+	b := NewWithContextStore(newMockStore(), contextStore)
+
+	if err := b.Jump("test123", &mockExecutor{}); err == nil {
+		t.Error("Jump() expected error for unknown context, got nil")
+	}
+}
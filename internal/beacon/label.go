@@ -0,0 +1,158 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidLabel is returned for labels and selectors that cannot be parsed.
+var ErrInvalidLabel = errors.New("invalid label")
+
+// Labels are key/value pairs attached to a beacon, such as project=web.
+type Labels map[string]string
+
+// ParseLabels parses key=value pairs into Labels.
+// Keys must be non-empty; keys and values may contain ASCII letters, digits
+// and any of ".-_/:@". A later pair overrides an earlier one with the same key.
+func ParseLabels(pairs []string) (Labels, error) {
+	labels := Labels{}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w %q: must be key=value", ErrInvalidLabel, pair)
+		}
+		if err := validateLabel(key, value); err != nil {
+			return nil, fmt.Errorf("%w %q: %s", ErrInvalidLabel, pair, err)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// String returns the labels as comma-separated key=value pairs sorted by key.
+func (l Labels) String() string {
+	pairs := make([]string, 0, len(l))
+	for _, key := range slices.Sorted(maps.Keys(l)) {
+		pairs = append(pairs, key+"="+l[key])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func validateLabel(key, value string) error {
+	if key == "" {
+		return errors.New("key must not be empty")
+	}
+	if !isLabelText(key) {
+		return errors.New("key contains invalid characters")
+	}
+	if !isLabelText(value) {
+		return errors.New("value contains invalid characters")
+	}
+	return nil
+}
+
+func isLabelText(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte(".-_/:@", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requirement is a single condition of a Selector.
+type requirement struct {
+	key   string
+	op    string // "=", "!=", "exists" or "!exists"
+	value string
+}
+
+// Selector selects beacons by their labels. All requirements must hold.
+type Selector []requirement
+
+// ParseSelector parses label requirements of the forms key=value (or
+// key==value), key!=value, key (the label is set) and !key (it is not).
+func ParseSelector(exprs []string) (Selector, error) {
+	var selector Selector
+	for _, expr := range exprs {
+		var r requirement
+		switch {
+		case strings.Contains(expr, "!="):
+			r.key, r.value, _ = strings.Cut(expr, "!=")
+			r.op = "!="
+		case strings.Contains(expr, "="):
+			r.key, r.value, _ = strings.Cut(expr, "=")
+			r.value = strings.TrimPrefix(r.value, "=")
+			r.op = "="
+		case strings.HasPrefix(expr, "!"):
+			r.key, r.op = expr[1:], "!exists"
+		default:
+			r.key, r.op = expr, "exists"
+		}
+		if err := validateLabel(r.key, r.value); err != nil {
+			return nil, fmt.Errorf("%w selector %q: %s", ErrInvalidLabel, expr, err)
+		}
+		selector = append(selector, r)
+	}
+	return selector, nil
+}
+
+// Matches reports whether labels satisfy every requirement of the selector.
+// An empty selector matches everything.
+func (s Selector) Matches(labels Labels) bool {
+	for _, r := range s {
+		value, ok := labels[r.key]
+		switch r.op {
+		case "=":
+			if !ok || value != r.value {
+				return false
+			}
+		case "!=":
+			if ok && value == r.value {
+				return false
+			}
+		case "exists":
+			if !ok {
+				return false
+			}
+		case "!exists":
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SilenceSelected silences every beacon whose labels match selector,
+// including expired ones, and returns their IDs sorted.
+// An empty selector is rejected rather than silencing every beacon.
+func (b *Beacon) SilenceSelected(selector Selector) ([]string, error) {
+	if len(selector) == 0 {
+		return nil, fmt.Errorf("%w selector: must not be empty", ErrInvalidLabel)
+	}
+	states, err := b.store.List()
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, state := range states {
+		if selector.Matches(state.Labels) {
+			ids = append(ids, state.ID)
+		}
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		if err := b.Silence(id); err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
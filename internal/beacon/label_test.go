@@ -0,0 +1,111 @@
+package beacon
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels([]string{"project=web", "agent=claude", "repo=org/app", "empty=", "project=api"})
+	if err != nil {
+		t.Fatalf("ParseLabels() error = %v", err)
+	}
+	if got := labels.String(); got != "agent=claude,empty=,project=api,repo=org/app" {
+		t.Errorf("ParseLabels() = %s", got)
+	}
+
+	for _, pair := range []string{"project", "=web", "pro ject=web", "project=a,b", "kind=ü"} {
+		if _, err := ParseLabels([]string{pair}); !errors.Is(err, ErrInvalidLabel) {
+			t.Errorf("ParseLabels(%q) error = %v, want ErrInvalidLabel", pair, err)
+		}
+	}
+}
+
+func TestSelector_Matches(t *testing.T) {
+	labels := Labels{"project": "web", "agent": "claude"}
+	tests := []struct {
+		exprs []string
+		want  bool
+	}{
+		{nil, true},
+		{[]string{"project=web"}, true},
+		{[]string{"project==web"}, true},
+		{[]string{"project=api"}, false},
+		{[]string{"project!=api"}, true},
+		{[]string{"project!=web"}, false},
+		{[]string{"kind!=approval"}, true},
+		{[]string{"agent"}, true},
+		{[]string{"kind"}, false},
+		{[]string{"!kind"}, true},
+		{[]string{"!agent"}, false},
+		{[]string{"project=web", "agent=claude"}, true},
+		{[]string{"project=web", "agent=codex"}, false},
+	}
+	for _, tt := range tests {
+		selector, err := ParseSelector(tt.exprs)
+		if err != nil {
+			t.Errorf("ParseSelector(%q) error = %v", tt.exprs, err)
+			continue
+		}
+		if got := selector.Matches(labels); got != tt.want {
+			t.Errorf("ParseSelector(%q).Matches() = %v, want %v", tt.exprs, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "=web", "!", "pro ject=web"} {
+		if _, err := ParseSelector([]string{expr}); !errors.Is(err, ErrInvalidLabel) {
+			t.Errorf("ParseSelector(%q) error = %v, want ErrInvalidLabel", expr, err)
+		}
+	}
+}
+
+func TestBeacon_Emit_Labels(t *testing.T) {
+	store := newMockStore()
+	b := New(store)
+
+	if err := b.Emit("test123", Emission{Message: "m", Labels: Labels{"project": "web", "kind": "approval"}}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	if err := b.Emit("test123", Emission{Message: "m", Labels: Labels{"kind": "question"}}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	if err := b.Emit("test123", Emission{Message: "m"}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+
+	if got := store.states["test123"].Labels.String(); got != "kind=question,project=web" {
+		t.Errorf("Labels = %s, want kind=question,project=web", got)
+	}
+}
+
+func TestBeacon_SilenceSelected(t *testing.T) {
+	store := newMockStore()
+	store.states["a"] = State{ID: "a", Labels: Labels{"project": "web"}}
+	store.states["b"] = State{ID: "b", Labels: Labels{"project": "api"}}
+	store.states["c"] = State{ID: "c", Labels: Labels{"project": "web"}}
+	store.states["d"] = State{ID: "d"}
+	contextStore := newMockContextStore()
+	b := NewWithContextStore(store, contextStore)
+
+	selector, err := ParseSelector([]string{"project=web"})
+	if err != nil {
+		t.Fatalf("ParseSelector() error = %v", err)
+	}
+	ids, err := b.SilenceSelected(selector)
+	if err != nil {
+		t.Fatalf("SilenceSelected() error = %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "a" || ids[1] != "c" {
+		t.Errorf("SilenceSelected() = %v, want [a c]", ids)
+	}
+	if len(store.states) != 2 {
+		t.Errorf("remaining states = %d, want 2", len(store.states))
+	}
+
+	if _, err := b.SilenceSelected(nil); !errors.Is(err, ErrInvalidLabel) {
+		t.Errorf("SilenceSelected(nil) error = %v, want ErrInvalidLabel", err)
+	}
+	if len(store.states) != 2 {
+		t.Errorf("empty selector removed beacons")
+	}
+}
@@ -0,0 +1,104 @@
+package beacon
+
+import (
+	"time"
+
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// Notifier is told about beacons being emitted and silenced, for example to
+// show a desktop notification. Emits are reported as EventAdded or
+// EventUpdated and silences as EventRemoved with the last known state.
+// ctx is the context of the beacon, or nil if it has none.
+type Notifier interface {
+	Notify(event Event, ctx context.Context) error
+}
+
+// Payload is the JSON document describing an event to webhooks and other
+// external receivers.
+type Payload struct {
+	Event     EventType `json:"event"`
+	ID        string    `json:"id"`
+	Status    Status    `json:"status"`
+	Message   string    `json:"message"`
+	Labels    Labels    `json:"labels,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	// Context holds the context fields keyed by context type, as in Record.
+	Context  map[string]any `json:"context,omitempty"`
+	Location string         `json:"location,omitempty"`
+}
+
+// NewPayload describes event as it happened at now. ctx may be nil.
+func NewPayload(event Event, ctx context.Context, now time.Time) Payload {
+	p := Payload{
+		Event:     event.Type,
+		ID:        event.ID,
+		Status:    event.State.Status,
+		Message:   event.State.Message,
+		Labels:    event.State.Labels,
+		Timestamp: now,
+	}
+	if ctx != nil {
+		if data, err := ctx.ToJSON(); err == nil {
+			p.Context, p.Location = describeContext(ctx, data)
+		}
+	}
+	return p
+}
+
+// AddNotifier registers n to be told about emits and silences.
+// Notifiers are best effort: their errors are passed to the handler set
+// with OnNotifyError and never fail an emit or silence.
+func (b *Beacon) AddNotifier(n Notifier) {
+	b.notifiers = append(b.notifiers, n)
+}
+
+// OnNotifyError sets the handler receiving notifier errors. By default they are dropped.
+func (b *Beacon) OnNotifyError(fn func(error)) {
+	b.notifyErr = fn
+}
+
+// update applies the emission and returns the event describing it, with
+// the state as the store wrote it.
+func (b *Beacon) update(id string, e Emission) (Event, error) {
+	eventType := EventUpdated
+	state, err := b.store.Update(id, func(state *State) error {
+		if state.EmitCount == 0 {
+			eventType = EventAdded
+		}
+		return e.apply(state)
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{Type: eventType, ID: id, State: state}, nil
+}
+
+// notify passes event to every notifier. If ctx is nil, the stored context is used.
+func (b *Beacon) notify(event Event, ctx context.Context) {
+	if len(b.notifiers) == 0 {
+		return
+	}
+	if ctx == nil {
+		ctx = b.storedContext(event.ID)
+	}
+	for _, n := range b.notifiers {
+		if err := n.Notify(event, ctx); err != nil && b.notifyErr != nil {
+			b.notifyErr(err)
+		}
+	}
+}
+
+// lastState returns the stored state of id, including an expired one.
+func (b *Beacon) lastState(id string) (State, bool) {
+	states, err := b.store.List()
+	if err != nil {
+		return State{}, false
+	}
+	for _, state := range states {
+		if state.ID == id {
+			return state, true
+		}
+	}
+	return State{}, false
+}
@@ -0,0 +1,124 @@
+package beacon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+type recordingNotifier struct {
+	events   []Event
+	contexts []context.Context
+	err      error
+}
+
+func (n *recordingNotifier) Notify(event Event, ctx context.Context) error {
+	n.events = append(n.events, event)
+	n.contexts = append(n.contexts, ctx)
+	return n.err
+}
+
+func TestBeacon_Notifiers(t *testing.T) {
+	b := NewWithContextStore(newMockStore(), newMockContextStore())
+	notifier := &recordingNotifier{}
+	b.AddNotifier(notifier)
+	ctx := &context.TmuxContext{SessionName: "main", PaneID: "%3"}
+
+	if err := b.EmitWithContext("test123", Emission{Message: "first"}, ctx); err != nil {
+		t.Fatalf("EmitWithContext() error = %v", err)
+	}
+	if err := b.Emit("test123", Emission{Message: "second", Status: StatusBlocked}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	if err := b.Silence("test123"); err != nil {
+		t.Fatalf("Silence() error = %v", err)
+	}
+	// Silencing an unknown beacon tells nobody.
+	if err := b.Silence("unknown"); err != nil {
+		t.Fatalf("Silence() error = %v", err)
+	}
+
+	want := []struct {
+		eventType EventType
+		message   string
+		status    Status
+		emits     int
+	}{
+		{EventAdded, "first", StatusWaiting, 1},
+		{EventUpdated, "second", StatusBlocked, 2},
+		{EventRemoved, "second", StatusBlocked, 2},
+	}
+	if len(notifier.events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(notifier.events), len(want), notifier.events)
+	}
+	for i, w := range want {
+		event := notifier.events[i]
+		if event.Type != w.eventType || event.ID != "test123" || event.State.Message != w.message ||
+			event.State.Status != w.status || event.State.EmitCount != w.emits {
+			t.Errorf("event %d = %+v, want %+v", i, event, w)
+		}
+		// The stored context is passed along when the emit has none.
+		if tmux, ok := notifier.contexts[i].(*context.TmuxContext); !ok || tmux.PaneID != "%3" {
+			t.Errorf("event %d context = %#v, want the tmux context", i, notifier.contexts[i])
+		}
+	}
+}
+
+func TestBeacon_Notifiers_BestEffort(t *testing.T) {
+	store := newMockStore()
+	b := New(store)
+	b.AddNotifier(&recordingNotifier{err: errors.New("bus unreachable")})
+	var reported []error
+	b.OnNotifyError(func(err error) { reported = append(reported, err) })
+
+	if err := b.Emit("test123", Emission{Message: "m"}); err != nil {
+		t.Fatalf("Emit() error = %v, want nil despite notifier failure", err)
+	}
+	if err := b.Silence("test123"); err != nil {
+		t.Fatalf("Silence() error = %v, want nil despite notifier failure", err)
+	}
+	if len(reported) != 2 {
+		t.Errorf("reported %d errors, want 2", len(reported))
+	}
+	if _, ok := store.states["test123"]; ok {
+		t.Error("Silence did not delete the state")
+	}
+}
+
+func TestBeacon_Notifiers_RejectedEmit(t *testing.T) {
+	b := New(newMockStore())
+	notifier := &recordingNotifier{}
+	b.AddNotifier(notifier)
+
+	if err := b.Emit("test123", Emission{Message: "m", Status: StatusDone}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	if err := b.Emit("test123", Emission{Message: "m", Status: StatusBlocked}); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("Emit() error = %v, want ErrInvalidTransition", err)
+	}
+	if len(notifier.events) != 1 {
+		t.Errorf("got %d events, want only the accepted emit", len(notifier.events))
+	}
+}
+
+func TestBeacon_Notifiers_WrittenState(t *testing.T) {
+	store := NewFileStoreWithDir(t.TempDir())
+	b := New(store)
+	notifier := &recordingNotifier{}
+	b.AddNotifier(notifier)
+
+	if err := b.Emit("test123", Emission{Message: "m"}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	states, err := store.List()
+	if err != nil || len(states) != 1 {
+		t.Fatalf("List() = %v, %v", states, err)
+	}
+	// Notifiers see the state as the store wrote it, not a copy of its stamping.
+	got, written := notifier.events[0].State, states[0]
+	if got.Version != written.Version || !got.UpdatedAt.Equal(written.UpdatedAt) || !got.CreatedAt.Equal(written.CreatedAt) ||
+		got.EmitCount != written.EmitCount || got.Hostname != written.Hostname || got.EmitterPID != written.EmitterPID {
+		t.Errorf("event state = %+v, want the written state %+v", got, written)
+	}
+}
@@ -0,0 +1,89 @@
+package beacon
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// Record is a beacon state joined with its decoded context.
+type Record struct {
+	State
+	// Context holds the context fields keyed by context type,
+	// e.g. Context["tmux"]["session_name"]. An agent session in tmux has
+	// both "agent" and "tmux" fields. Nil if no context was recorded.
+	Context map[string]any `json:"context,omitempty"`
+	// Location is a short description of where the agent runs, such as "main:1.0".
+	Location string `json:"location,omitempty"`
+	// Profile is the namespace the beacon belongs to. It is only set when
+	// beacons of several profiles are listed together.
+	Profile string `json:"profile,omitempty"`
+}
+
+// Records wraps states in records without context.
+func Records(states []State) []Record {
+	records := make([]Record, len(states))
+	for i, state := range states {
+		records[i] = Record{State: state}
+	}
+	return records
+}
+
+// ListWithContext returns all active beacons sorted by ID, each joined with
+// its context. Beacons without context, or whose context is not of a known
+// type, are returned with a nil Context.
+func (b *Beacon) ListWithContext() ([]Record, error) {
+	states, err := b.List()
+	if err != nil {
+		return nil, err
+	}
+	records := Records(states)
+	if b.contextStore == nil {
+		return records, nil
+	}
+	for i := range records {
+		data, err := b.contextStore.Read(records[i].ID)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ctx, err := context.Decode(data)
+		if err != nil {
+			continue
+		}
+		records[i].Context, records[i].Location = describeContext(ctx, data)
+	}
+	return records, nil
+}
+
+// describeContext returns the fields of ctx keyed by its type and its
+// location, given the JSON form of ctx. The parts of a composite context
+// are added under their own types, so the pane of an agent session is found
+// under "tmux" like that of any other beacon.
+func describeContext(ctx context.Context, data []byte) (map[string]any, string) {
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, ""
+	}
+	described := map[string]any{ctx.Type(): fields}
+	if composite, ok := ctx.(context.Composite); ok {
+		for _, part := range composite.Parts() {
+			data, err := part.ToJSON()
+			if err != nil {
+				continue
+			}
+			var partFields map[string]any
+			if err := json.Unmarshal(data, &partFields); err == nil {
+				described[part.Type()] = partFields
+			}
+		}
+	}
+	var location string
+	if locator, ok := ctx.(context.Locator); ok {
+		location = locator.Location()
+	}
+	return described, location
+}
@@ -0,0 +1,99 @@
+package beacon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+func TestBeacon_ListWithContext(t *testing.T) {
+	store := newMockStore()
+	store.states["with"] = State{ID: "with", Message: "m1", Status: StatusWaiting}
+	store.states["without"] = State{ID: "without", Message: "m2", Status: StatusRunning}
+	store.states["unknown"] = State{ID: "unknown", Message: "m3", Status: StatusRunning}
+	contextStore := newMockContextStore()
+	contextStore.contexts["with"] = &context.TmuxContext{SessionName: "main", WindowIndex: 1, PaneIndex: 2, PaneID: "%3"}
+	contextStore.contexts["unknown"] = &mockContext{contextType: "other", json: []byte(`{"foo":"bar"}`)}
+	b := NewWithContextStore(store, contextStore)
+
+	records, err := b.ListWithContext()
+	if err != nil {
+		t.Fatalf("ListWithContext() error = %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("ListWithContext() len = %d, want 3", len(records))
+	}
+
+	unknown, with, without := records[0], records[1], records[2]
+	tmux, ok := with.Context["tmux"].(map[string]any)
+	if !ok {
+		t.Fatalf("Context = %#v, want tmux fields", with.Context)
+	}
+	if tmux["session_name"] != "main" || tmux["pane_id"] != "%3" {
+		t.Errorf("tmux context = %v", tmux)
+	}
+	if with.Location != "main:1.2" {
+		t.Errorf("Location = %q, want %q", with.Location, "main:1.2")
+	}
+	if with.Message != "m1" {
+		t.Errorf("Message = %q, want %q", with.Message, "m1")
+	}
+	if without.Context != nil || without.Location != "" {
+		t.Errorf("record without context = %+v", without)
+	}
+	if unknown.Context != nil {
+		t.Errorf("record with unknown context = %+v", unknown)
+	}
+}
+
+func TestBeacon_ListWithContext_Agent(t *testing.T) {
+	store := newMockStore()
+	store.states["session"] = State{ID: "session", Status: StatusWaiting}
+	contextStore := newMockContextStore()
+	contextStore.contexts["session"] = &context.AgentContext{
+		Agent:       "claude",
+		Cwd:         "/src/web",
+		TmuxContext: &context.TmuxContext{SessionName: "api", WindowIndex: 2, PaneID: "%7"},
+	}
+	b := NewWithContextStore(store, contextStore)
+
+	records, err := b.ListWithContext()
+	if err != nil {
+		t.Fatalf("ListWithContext() error = %v", err)
+	}
+	agent, _ := records[0].Context["agent"].(map[string]any)
+	tmux, _ := records[0].Context["tmux"].(map[string]any)
+	if agent["cwd"] != "/src/web" || tmux["session_name"] != "api" || tmux["pane_id"] != "%7" {
+		t.Errorf("Context = %#v, want agent and tmux fields", records[0].Context)
+	}
+	if _, ok := tmux["agent"]; ok {
+		t.Errorf("tmux fields include the agent: %v", tmux)
+	}
+}
+
+func TestBeacon_ListWithContext_NoContextStore(t *testing.T) {
+	store := newMockStore()
+	store.states["test123"] = State{ID: "test123", Status: StatusWaiting}
+	b := New(store)
+
+	records, err := b.ListWithContext()
+	if err != nil {
+		t.Fatalf("ListWithContext() error = %v", err)
+	}
+	if len(records) != 1 || records[0].Context != nil {
+		t.Errorf("ListWithContext() = %+v", records)
+	}
+}
+
+func TestBeacon_ListWithContext_ReadError(t *testing.T) {
+	store := newMockStore()
+	store.states["test123"] = State{ID: "test123", Status: StatusWaiting}
+	contextStore := newMockContextStore()
+	contextStore.contexts["test123"] = &mockContext{contextType: "other", jsonErr: errors.New("read error")}
+	b := NewWithContextStore(store, contextStore)
+
+	if _, err := b.ListWithContext(); err == nil {
+		t.Error("ListWithContext() expected error, got nil")
+	}
+}
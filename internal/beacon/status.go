@@ -0,0 +1,57 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrInvalidTransition is returned when a beacon cannot move to the requested status.
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// Status is the lifecycle status of the agent behind a beacon.
+type Status string
+
+const (
+	StatusRunning Status = "running"
+	StatusWaiting Status = "waiting-for-input"
+	StatusBlocked Status = "blocked"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// DefaultStatus is used when an emit does not specify a status.
+// A beacon without a status has always meant that the agent needs attention.
+const DefaultStatus = StatusWaiting
+
+// Statuses lists all known statuses.
+var Statuses = []Status{StatusRunning, StatusWaiting, StatusBlocked, StatusDone, StatusFailed}
+
+// transitions lists the statuses each status may move to besides itself.
+var transitions = map[Status][]Status{
+	StatusRunning: {StatusWaiting, StatusBlocked, StatusDone, StatusFailed},
+	StatusWaiting: {StatusRunning, StatusBlocked, StatusDone, StatusFailed},
+	StatusBlocked: {StatusRunning, StatusWaiting, StatusDone, StatusFailed},
+	StatusDone:    {StatusRunning, StatusWaiting},
+	StatusFailed:  {StatusRunning, StatusWaiting},
+}
+
+// ParseStatus returns the Status named by s.
+func ParseStatus(s string) (Status, error) {
+	status := Status(s)
+	if !slices.Contains(Statuses, status) {
+		return "", fmt.Errorf("unknown status: %s", s)
+	}
+	return status, nil
+}
+
+// CanTransitionTo reports whether a beacon in status s may move to next.
+// Repeating the current status is always allowed.
+func (s Status) CanTransitionTo(next Status) bool {
+	return s == next || slices.Contains(transitions[s], next)
+}
+
+// NeedsAttention reports whether the agent is waiting on the user.
+func (s Status) NeedsAttention() bool {
+	return s == StatusWaiting || s == StatusBlocked
+}
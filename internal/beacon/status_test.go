@@ -0,0 +1,56 @@
+package beacon
+
+import "testing"
+
+func TestParseStatus(t *testing.T) {
+	for _, s := range Statuses {
+		got, err := ParseStatus(string(s))
+		if err != nil {
+			t.Errorf("ParseStatus(%q) error = %v", s, err)
+		}
+		if got != s {
+			t.Errorf("ParseStatus(%q) = %q", s, got)
+		}
+	}
+
+	if _, err := ParseStatus("sleeping"); err == nil {
+		t.Error("ParseStatus() expected error for unknown status, got nil")
+	}
+}
+
+func TestStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to Status
+		want     bool
+	}{
+		{StatusRunning, StatusWaiting, true},
+		{StatusRunning, StatusRunning, true},
+		{StatusWaiting, StatusRunning, true},
+		{StatusBlocked, StatusDone, true},
+		{StatusDone, StatusRunning, true},
+		{StatusDone, StatusFailed, false},
+		{StatusDone, StatusBlocked, false},
+		{StatusFailed, StatusDone, false},
+		{StatusFailed, StatusWaiting, true},
+	}
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestStatus_NeedsAttention(t *testing.T) {
+	want := map[Status]bool{
+		StatusRunning: false,
+		StatusWaiting: true,
+		StatusBlocked: true,
+		StatusDone:    false,
+		StatusFailed:  false,
+	}
+	for s, w := range want {
+		if got := s.NeedsAttention(); got != w {
+			t.Errorf("%s.NeedsAttention() = %v, want %v", s, got, w)
+		}
+	}
+}
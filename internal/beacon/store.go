@@ -1,22 +1,55 @@
 package beacon
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/monochromegane/beacon/internal/process"
 	"github.com/monochromegane/beacon/internal/storage"
 )
 
+// staleTempAge is how old a leftover temporary file must be before a writer removes it.
+const staleTempAge = time.Minute
+
+// errCorruptState is returned for state files that were truncated or otherwise damaged.
+var errCorruptState = errors.New("corrupt state file")
+
+// StateVersion is the version of the state record format written by FileStore.
+// Version 0 denotes a legacy plain-text state file.
+const StateVersion = 1
+
 // State represents the content of a beacon state file.
 type State struct {
-	ID      string
-	Message string
+	Version    int             `json:"version"`
+	ID         string          `json:"id"`
+	Message    string          `json:"message"`
+	Status     Status          `json:"status"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	EmitCount  int             `json:"emit_count"`
+	EmitterPID int             `json:"emitter_pid"`
+	Hostname   string          `json:"hostname"`
+	ExpiresAt  time.Time       `json:"expires_at,omitzero"`
+	Owner      process.Process `json:"owner,omitzero"`
+	Labels     Labels          `json:"labels,omitempty"`
+}
+
+// Expired reports whether the state has an expiry time at or before now.
+func (s State) Expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
 }
 
 // Store is an interface for file operations (mockable for tests).
+// Update applies fn to the current state for the given ID (a zero State
+// with EmitCount 0 if none exists), persists the result as a new emit and
+// returns the state as written. If fn returns an error, nothing is written.
 type Store interface {
-	Write(id string, message string) error
+	Update(id string, fn func(state *State) error) (State, error)
 	Delete(id string) error
 	List() ([]State, error)
 }
@@ -24,40 +57,126 @@ type Store interface {
 // FileStore is the production implementation of Store.
 type FileStore struct {
 	baseDir string
+	now     func() time.Time
 }
 
 // NewFileStore creates a new FileStore with the resolved base directory.
 func NewFileStore() (*FileStore, error) {
-	baseDir, err := storage.ResolveBaseDir()
+	return NewFileStoreAt(storage.Location{})
+}
+
+// NewFileStoreAt creates a new FileStore for the directory of loc.
+func NewFileStoreAt(loc storage.Location) (*FileStore, error) {
+	baseDir, err := loc.Dir()
 	if err != nil {
 		return nil, err
 	}
-	return &FileStore{baseDir: baseDir}, nil
+	return NewFileStoreWithDir(baseDir), nil
 }
 
-// NewFileStoreWithDir creates a new FileStore with a custom base directory (for testing).
+// NewFileStoreWithDir creates a new FileStore with a custom base directory.
 func NewFileStoreWithDir(baseDir string) *FileStore {
-	return &FileStore{baseDir: baseDir}
+	return &FileStore{baseDir: baseDir, now: time.Now}
+}
+
+// Update creates or updates the state file for the given ID.
+// The creation time and emit count of an existing state are carried over.
+// The read-modify-write cycle runs under an exclusive lock on the base
+// directory and the file is replaced atomically. A file written under the
+// unencoded name of the ID is migrated to the encoded one.
+func (s *FileStore) Update(id string, fn func(state *State) error) (State, error) {
+	path, legacyPath, err := s.paths(id)
+	if err != nil {
+		return State{}, err
+	}
+	lock, err := storage.LockDir(s.baseDir)
+	if err != nil {
+		return State{}, err
+	}
+	defer lock.Unlock()
+
+	storage.RemoveStaleTemp(s.baseDir, staleTempAge)
+
+	now := s.now()
+	state := State{ID: id, CreatedAt: now}
+	prev, err := readState(path, id)
+	if os.IsNotExist(err) && legacyPath != "" {
+		prev, err = readState(legacyPath, id)
+	}
+	switch {
+	case err == nil:
+		state = prev
+	case os.IsNotExist(err), errors.Is(err, errCorruptState):
+		// Start over; a corrupt file is repaired by the write below.
+	default:
+		return State{}, err
+	}
+
+	if err := fn(&state); err != nil {
+		return State{}, err
+	}
+
+	hostname, _ := os.Hostname()
+	state.Version = StateVersion
+	state.ID = id
+	state.UpdatedAt = now
+	state.EmitCount++
+	state.EmitterPID = os.Getpid()
+	state.Hostname = hostname
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return State{}, err
+	}
+	if err := storage.WriteFileAtomic(path, append(data, '\n'), 0644); err != nil {
+		return State{}, err
+	}
+	if err := storage.RemoveFiles(legacyPath); err != nil {
+		return State{}, err
+	}
+	return state, nil
 }
 
-// Write creates or updates a state file for the given ID.
+// Write creates or updates the state file for the given ID with a message.
 func (s *FileStore) Write(id string, message string) error {
-	if err := os.MkdirAll(s.baseDir, 0755); err != nil {
+	_, err := s.Update(id, func(state *State) error {
+		state.Message = message
+		if state.Status == "" {
+			state.Status = DefaultStatus
+		}
+		return nil
+	})
+	return err
+}
+
+// Delete removes the state file for the given ID, including one written
+// under its unencoded name. Returns nil if the file does not exist (idempotent).
+func (s *FileStore) Delete(id string) error {
+	path, legacyPath, err := s.paths(id)
+	if err != nil {
 		return err
 	}
-	path := filepath.Join(s.baseDir, id)
-	return os.WriteFile(path, []byte(message), 0644)
+	lock, err := storage.LockDir(s.baseDir)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	return storage.RemoveFiles(path, legacyPath)
 }
 
-// Delete removes the state file for the given ID.
-// Returns nil if the file does not exist (idempotent).
-func (s *FileStore) Delete(id string) error {
-	path := filepath.Join(s.baseDir, id)
-	err := os.Remove(path)
-	if os.IsNotExist(err) {
-		return nil
+// paths returns the state file path for the given ID and, if the ID has
+// one, the path it had before IDs were encoded.
+func (s *FileStore) paths(id string) (string, string, error) {
+	parsed, err := storage.ParseID(id)
+	if err != nil {
+		return "", "", err
 	}
-	return err
+	var legacyPath string
+	if name, ok := parsed.LegacyFilename(); ok {
+		legacyPath = filepath.Join(s.baseDir, name)
+	}
+	return filepath.Join(s.baseDir, parsed.Filename()), legacyPath, nil
 }
 
 // List returns all states from beacon files in the base directory.
@@ -72,23 +191,89 @@ func (s *FileStore) List() ([]State, error) {
 	}
 
 	var states []State
+	// index maps IDs to their position in states, so that a legacy file
+	// left next to the encoded one is listed once.
+	index := make(map[string]int)
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 		name := entry.Name()
-		// Skip JSON context files
-		if strings.HasSuffix(name, ".json") {
+		// Skip JSON context files, lock files and in-flight temporary files
+		if strings.HasSuffix(name, ".json") || storage.IsHidden(name) {
+			continue
+		}
+		id, err := storage.DecodeFilename(name)
+		if err != nil {
 			continue
 		}
-		content, err := os.ReadFile(filepath.Join(s.baseDir, name))
+		state, err := readState(filepath.Join(s.baseDir, name), id.String())
 		if err != nil {
+			// Skip files removed concurrently or left corrupt by a crash
+			continue
+		}
+		if i, ok := index[state.ID]; ok {
+			if name == id.Filename() {
+				states[i] = state
+			}
 			continue
 		}
-		states = append(states, State{
-			ID:      name,
-			Message: strings.TrimSpace(string(content)),
-		})
+		index[state.ID] = len(states)
+		states = append(states, state)
 	}
 	return states, nil
 }
+
+// recordPrefix starts every state record written by FileStore. Content
+// without it is a legacy plain-text message, even if it starts with "{".
+var recordPrefix = []byte(`{"version":`)
+
+// readState reads a state file, accepting both the JSON record format and
+// the legacy plain-text format that only contains the message.
+// Empty files and JSON records that fail to decode are reported as errCorruptState.
+func readState(path string, id string) (State, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return State{}, err
+	}
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 {
+		return State{}, errCorruptState
+	}
+	if bytes.HasPrefix(trimmed, recordPrefix) {
+		state, err := decodeState(trimmed)
+		if err != nil {
+			return State{}, errCorruptState
+		}
+		state.ID = id
+		if state.Status == "" {
+			state.Status = DefaultStatus
+		}
+		return state, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return State{}, err
+	}
+	return State{
+		ID:        id,
+		Message:   string(trimmed),
+		Status:    DefaultStatus,
+		CreatedAt: info.ModTime(),
+		UpdatedAt: info.ModTime(),
+		EmitCount: 1,
+	}, nil
+}
+
+// decodeState parses a JSON state record.
+func decodeState(content []byte) (State, error) {
+	var state State
+	if err := json.Unmarshal(content, &state); err != nil {
+		return State{}, err
+	}
+	if state.Version < 1 {
+		return State{}, errors.New("not a versioned state record")
+	}
+	return state, nil
+}
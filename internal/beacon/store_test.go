@@ -1,11 +1,36 @@
 package beacon
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/storage"
 )
 
+func readTestState(t *testing.T, path string) State {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var state State
+	if err := json.Unmarshal(content, &state); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return state
+}
+
 func TestFileStore_Write(t *testing.T) {
 	tmpDir := t.TempDir()
 	store := NewFileStoreWithDir(tmpDir)
@@ -15,12 +40,24 @@ func TestFileStore_Write(t *testing.T) {
 		t.Fatalf("Write() error = %v", err)
 	}
 
-	content, err := os.ReadFile(filepath.Join(tmpDir, "test123"))
-	if err != nil {
-		t.Fatalf("ReadFile() error = %v", err)
+	state := readTestState(t, filepath.Join(tmpDir, "test123"))
+	if state.Message != "test message" {
+		t.Errorf("Write() message = %q, want %q", state.Message, "test message")
+	}
+	if state.Version != StateVersion {
+		t.Errorf("Write() version = %d, want %d", state.Version, StateVersion)
+	}
+	if state.ID != "test123" {
+		t.Errorf("Write() id = %q, want %q", state.ID, "test123")
+	}
+	if state.EmitCount != 1 {
+		t.Errorf("Write() emit_count = %d, want 1", state.EmitCount)
 	}
-	if string(content) != "test message" {
-		t.Errorf("Write() content = %q, want %q", string(content), "test message")
+	if state.EmitterPID != os.Getpid() {
+		t.Errorf("Write() emitter_pid = %d, want %d", state.EmitterPID, os.Getpid())
+	}
+	if state.CreatedAt.IsZero() || !state.CreatedAt.Equal(state.UpdatedAt) {
+		t.Errorf("Write() created_at = %v, updated_at = %v, want equal non-zero times", state.CreatedAt, state.UpdatedAt)
 	}
 }
 
@@ -34,12 +71,92 @@ func TestFileStore_Write_Overwrite(t *testing.T) {
 		t.Fatalf("Write() error = %v", err)
 	}
 
-	content, err := os.ReadFile(filepath.Join(tmpDir, "test123"))
+	state := readTestState(t, filepath.Join(tmpDir, "test123"))
+	if state.Message != "second message" {
+		t.Errorf("Write() message = %q, want %q", state.Message, "second message")
+	}
+	if state.EmitCount != 2 {
+		t.Errorf("Write() emit_count = %d, want 2", state.EmitCount)
+	}
+}
+
+func TestFileStore_Write_PreservesCreatedAt(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+
+	first := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	second := first.Add(5 * time.Minute)
+
+	store.now = func() time.Time { return first }
+	store.Write("test123", "first message")
+	store.now = func() time.Time { return second }
+	store.Write("test123", "second message")
+
+	state := readTestState(t, filepath.Join(tmpDir, "test123"))
+	if !state.CreatedAt.Equal(first) {
+		t.Errorf("Write() created_at = %v, want %v", state.CreatedAt, first)
+	}
+	if !state.UpdatedAt.Equal(second) {
+		t.Errorf("Write() updated_at = %v, want %v", state.UpdatedAt, second)
+	}
+}
+
+func TestFileStore_Write_UpgradesLegacyFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+
+	os.WriteFile(filepath.Join(tmpDir, "test123"), []byte("legacy message"), 0644)
+
+	err := store.Write("test123", "new message")
 	if err != nil {
-		t.Fatalf("ReadFile() error = %v", err)
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	state := readTestState(t, filepath.Join(tmpDir, "test123"))
+	if state.Version != StateVersion {
+		t.Errorf("Write() version = %d, want %d", state.Version, StateVersion)
 	}
-	if string(content) != "second message" {
-		t.Errorf("Write() content = %q, want %q", string(content), "second message")
+	if state.EmitCount != 2 {
+		t.Errorf("Write() emit_count = %d, want 2", state.EmitCount)
+	}
+}
+
+func TestFileStore_LegacyFilenames(t *testing.T) {
+	for _, id := range []string{"%1", "my.session"} {
+		t.Run(id, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			store := NewFileStoreWithDir(tmpDir)
+			legacyPath := filepath.Join(tmpDir, id)
+			os.WriteFile(legacyPath, []byte("legacy message\n"), 0644)
+
+			states, err := store.List()
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(states) != 1 || states[0].ID != id || states[0].Message != "legacy message" {
+				t.Fatalf("List() = %+v, want the legacy beacon %q", states, id)
+			}
+
+			// The first write migrates the file to its encoded name.
+			if err := store.Write(id, "new message"); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+				t.Errorf("legacy file still exists after Write(): %v", err)
+			}
+			states, _ = store.List()
+			if len(states) != 1 || states[0].Message != "new message" || states[0].EmitCount != 2 {
+				t.Errorf("List() after Write() = %+v, want one migrated beacon", states)
+			}
+
+			os.WriteFile(legacyPath, []byte("legacy message\n"), 0644)
+			if err := store.Delete(id); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if entries, _ := os.ReadDir(tmpDir); slices.ContainsFunc(entries, func(e os.DirEntry) bool { return !storage.IsHidden(e.Name()) }) {
+				t.Errorf("Delete() left files behind: %v", entries)
+			}
+		})
 	}
 }
 
@@ -142,3 +259,366 @@ func TestFileStore_List_IgnoresJSONFiles(t *testing.T) {
 		t.Errorf("List() state[0].ID = %s, want test123", states[0].ID)
 	}
 }
+
+func TestFileStore_List_LegacyPlainText(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+
+	path := filepath.Join(tmpDir, "legacy")
+	os.WriteFile(path, []byte("legacy message\n"), 0644)
+	mtime := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	os.Chtimes(path, mtime, mtime)
+
+	states, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(states) != 1 {
+		t.Fatalf("List() len = %d, want 1", len(states))
+	}
+	state := states[0]
+	if state.ID != "legacy" || state.Message != "legacy message" {
+		t.Errorf("List() state = %+v, want legacy message", state)
+	}
+	if state.Version != 0 {
+		t.Errorf("List() version = %d, want 0", state.Version)
+	}
+	if !state.UpdatedAt.Equal(mtime) {
+		t.Errorf("List() updated_at = %v, want %v", state.UpdatedAt, mtime)
+	}
+}
+
+func TestFileStore_LegacyPlainTextLikeJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+	for _, message := range []string{"{wip} approve?", `{"msg":"hi"}`} {
+		os.WriteFile(filepath.Join(tmpDir, "legacy"), []byte(message+"\n"), 0644)
+
+		states, err := store.List()
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(states) != 1 || states[0].Message != message || states[0].Version != 0 {
+			t.Errorf("List() = %+v, want the plain-text message %q", states, message)
+		}
+	}
+
+	if err := store.Write("legacy", "next"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if state := readTestState(t, filepath.Join(tmpDir, "legacy")); state.EmitCount != 2 {
+		t.Errorf("Write() emit_count = %d, want 2 carried over from the legacy file", state.EmitCount)
+	}
+}
+
+func TestFileStore_List_JSONRecord(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	store.Write("test123", "message")
+
+	states, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(states) != 1 {
+		t.Fatalf("List() len = %d, want 1", len(states))
+	}
+	hostname, _ := os.Hostname()
+	want := State{
+		Version:    StateVersion,
+		ID:         "test123",
+		Message:    "message",
+		Status:     DefaultStatus,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		EmitCount:  1,
+		EmitterPID: os.Getpid(),
+		Hostname:   hostname,
+	}
+	if !reflect.DeepEqual(states[0], want) {
+		t.Errorf("List() state = %+v, want %+v", states[0], want)
+	}
+}
+
+func TestFileStore_List_SkipsCorruptAndHiddenFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+
+	store.Write("valid", "message")
+	os.WriteFile(filepath.Join(tmpDir, "truncated"), []byte(`{"version":1,"id":"trunc`), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "empty"), nil, 0644)
+	os.WriteFile(filepath.Join(tmpDir, ".tmp-valid-123"), []byte(`{"version":1`), 0644)
+
+	states, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(states) != 1 {
+		t.Fatalf("List() len = %d, want 1", len(states))
+	}
+	if states[0].ID != "valid" {
+		t.Errorf("List() state[0].ID = %s, want valid", states[0].ID)
+	}
+}
+
+func TestFileStore_Write_RepairsCorruptFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+
+	os.WriteFile(filepath.Join(tmpDir, "test123"), []byte(`{"version":1,"mess`), 0644)
+
+	err := store.Write("test123", "repaired")
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	state := readTestState(t, filepath.Join(tmpDir, "test123"))
+	if state.Message != "repaired" {
+		t.Errorf("Write() message = %q, want %q", state.Message, "repaired")
+	}
+	if state.EmitCount != 1 {
+		t.Errorf("Write() emit_count = %d, want 1", state.EmitCount)
+	}
+}
+
+func TestFileStore_ConcurrentGoroutines(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+
+	const writers, writes = 20, 25
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			states, err := store.List()
+			if err != nil {
+				t.Errorf("List() error = %v", err)
+				return
+			}
+			for _, s := range states {
+				if s.Version != StateVersion || !strings.HasPrefix(s.Message, "message ") {
+					t.Errorf("List() observed partial state %+v", s)
+					return
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < writes; j++ {
+				if err := store.Write("shared", fmt.Sprintf("message %d-%d", i, j)); err != nil {
+					t.Errorf("Write() error = %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(done)
+	readers.Wait()
+
+	state := readTestState(t, filepath.Join(tmpDir, "shared"))
+	if state.EmitCount != writers*writes {
+		t.Errorf("emit_count = %d, want %d (lost updates)", state.EmitCount, writers*writes)
+	}
+}
+
+// TestHelperProcess is not a real test. It is executed as a child process by
+// TestFileStore_ConcurrentProcesses to write to a shared store.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("BEACON_TEST_HELPER_PROCESS") != "1" {
+		return
+	}
+	store := NewFileStoreWithDir(os.Getenv("BEACON_TEST_DIR"))
+	writes, _ := strconv.Atoi(os.Getenv("BEACON_TEST_WRITES"))
+	for j := 0; j < writes; j++ {
+		if err := store.Write("shared", fmt.Sprintf("message %d-%d", os.Getpid(), j)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+func TestFileStore_ConcurrentProcesses(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	const processes, writes = 8, 25
+	var cmds []*exec.Cmd
+	for i := 0; i < processes; i++ {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		cmd.Env = append(os.Environ(),
+			"BEACON_TEST_HELPER_PROCESS=1",
+			"BEACON_TEST_DIR="+tmpDir,
+			"BEACON_TEST_WRITES="+strconv.Itoa(writes),
+		)
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("helper process error = %v", err)
+		}
+	}
+
+	state := readTestState(t, filepath.Join(tmpDir, "shared"))
+	if state.EmitCount != processes*writes {
+		t.Errorf("emit_count = %d, want %d (lost updates)", state.EmitCount, processes*writes)
+	}
+}
+
+func TestFileStore_Write_EncodesUnsafeID(t *testing.T) {
+	tmpDir := t.TempDir()
+	baseDir := filepath.Join(tmpDir, "beacon")
+	store := NewFileStoreWithDir(baseDir)
+
+	ids := []string{"../../escape", "https://example.com/agent:1", "foo.json"}
+	for _, id := range ids {
+		if err := store.Write(id, "message"); err != nil {
+			t.Fatalf("Write(%q) error = %v", id, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "escape")); !os.IsNotExist(err) {
+		t.Error("Write() created a file outside the base directory")
+	}
+
+	states, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	got := make(map[string]bool)
+	for _, s := range states {
+		got[s.ID] = true
+	}
+	for _, id := range ids {
+		if !got[id] {
+			t.Errorf("List() missing ID %q, got %v", id, got)
+		}
+	}
+
+	for _, id := range ids {
+		if err := store.Delete(id); err != nil {
+			t.Errorf("Delete(%q) error = %v", id, err)
+		}
+	}
+	states, _ = store.List()
+	if len(states) != 0 {
+		t.Errorf("List() len = %d after Delete, want 0", len(states))
+	}
+}
+
+func TestFileStore_InvalidID(t *testing.T) {
+	store := NewFileStoreWithDir(t.TempDir())
+
+	for _, id := range []string{"", "line\nbreak"} {
+		if err := store.Write(id, "message"); !errors.Is(err, storage.ErrInvalidID) {
+			t.Errorf("Write(%q) error = %v, want ErrInvalidID", id, err)
+		}
+		if err := store.Delete(id); !errors.Is(err, storage.ErrInvalidID) {
+			t.Errorf("Delete(%q) error = %v, want ErrInvalidID", id, err)
+		}
+	}
+}
+
+func TestFileStore_Update(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+
+	written, err := store.Update("test123", func(state *State) error {
+		if state.EmitCount != 0 {
+			t.Errorf("Update() new state emit_count = %d, want 0", state.EmitCount)
+		}
+		state.Message = "working"
+		state.Status = StatusRunning
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	state := readTestState(t, filepath.Join(tmpDir, "test123"))
+	if state.Status != StatusRunning || state.Message != "working" {
+		t.Errorf("Update() state = %+v, want running/working", state)
+	}
+	// The returned state is the one written, stamped by the store.
+	if written.EmitCount != 1 || written.UpdatedAt.IsZero() || !written.UpdatedAt.Equal(state.UpdatedAt) || written.Message != "working" {
+		t.Errorf("Update() returned %+v, want the written state %+v", written, state)
+	}
+}
+
+func TestFileStore_Update_Abort(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+	store.Write("test123", "original")
+
+	abort := errors.New("abort")
+	_, err := store.Update("test123", func(state *State) error {
+		state.Message = "changed"
+		return abort
+	})
+	if !errors.Is(err, abort) {
+		t.Fatalf("Update() error = %v, want %v", err, abort)
+	}
+
+	state := readTestState(t, filepath.Join(tmpDir, "test123"))
+	if state.Message != "original" || state.EmitCount != 1 {
+		t.Errorf("Update() state = %+v, want unchanged", state)
+	}
+}
+
+func TestState_Expired(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		want      bool
+	}{
+		{"no expiry", time.Time{}, false},
+		{"future", now.Add(time.Second), false},
+		{"now", now, true},
+		{"past", now.Add(-time.Second), true},
+	}
+	for _, tt := range tests {
+		if got := (State{ExpiresAt: tt.expiresAt}).Expired(now); got != tt.want {
+			t.Errorf("%s: Expired() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFileStore_Update_ExpiresAt(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+	expiresAt := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	store.Write("forever", "message")
+	store.Update("expiring", func(state *State) error {
+		state.ExpiresAt = expiresAt
+		return nil
+	})
+
+	content, _ := os.ReadFile(filepath.Join(tmpDir, "forever"))
+	if strings.Contains(string(content), "expires_at") {
+		t.Errorf("Write() without expiry wrote expires_at: %s", content)
+	}
+	state := readTestState(t, filepath.Join(tmpDir, "expiring"))
+	if !state.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("Update() expires_at = %v, want %v", state.ExpiresAt, expiresAt)
+	}
+}
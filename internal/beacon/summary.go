@@ -0,0 +1,170 @@
+package beacon
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/context"
+	"github.com/monochromegane/beacon/internal/storage"
+)
+
+// summaryCacheDir is the hidden directory in the base directory holding the
+// cached summary. Writing inside it does not change the base directory itself.
+const summaryCacheDir = ".cache"
+
+// racyRevisionAge is how old a revision must be before a summary is cached
+// for it. Directory timestamps are coarse, so a change made shortly after
+// the revision was read could leave it unchanged.
+const racyRevisionAge = 2 * time.Second
+
+// Summary counts the beacons that need attention.
+type Summary struct {
+	Waiting int `json:"waiting"`
+	Blocked int `json:"blocked"`
+	// Sessions counts beacons needing attention per tmux session.
+	Sessions map[string]int `json:"sessions,omitempty"`
+	// ValidUntil is the earliest expiry among active beacons, after which
+	// the summary must be recomputed. Zero means no beacon expires.
+	ValidUntil time.Time `json:"valid_until,omitzero"`
+}
+
+// Total returns the number of beacons needing attention.
+func (s Summary) Total() int {
+	return s.Waiting + s.Blocked
+}
+
+// SummaryCache is implemented by stores that can keep a Summary until their
+// content changes.
+type SummaryCache interface {
+	// Revision returns a token that changes whenever a beacon or its context
+	// is written or removed.
+	Revision() (string, error)
+	LoadSummary(revision string) (Summary, bool)
+	SaveSummary(revision string, summary Summary) error
+}
+
+// Summary returns counts of the beacons that need attention. If the store
+// is a SummaryCache, a cached summary is reused until the store changes or
+// a beacon expires.
+func (b *Beacon) Summary() (Summary, error) {
+	cache, ok := b.store.(SummaryCache)
+	if !ok {
+		return b.summarize()
+	}
+
+	// The revision is taken before reading, so changes made while the
+	// summary is computed invalidate it on the next call.
+	revision, err := cache.Revision()
+	if err != nil {
+		return b.summarize()
+	}
+	if summary, ok := cache.LoadSummary(revision); ok {
+		if summary.ValidUntil.IsZero() || b.now().Before(summary.ValidUntil) {
+			return summary, nil
+		}
+	}
+	summary, err := b.summarize()
+	if err != nil {
+		return Summary{}, err
+	}
+	// A failed cache write only costs a recomputation next time.
+	cache.SaveSummary(revision, summary)
+	return summary, nil
+}
+
+func (b *Beacon) summarize() (Summary, error) {
+	states, err := b.List()
+	if err != nil {
+		return Summary{}, err
+	}
+	summary := Summary{Sessions: map[string]int{}}
+	for _, state := range states {
+		if !state.ExpiresAt.IsZero() && (summary.ValidUntil.IsZero() || state.ExpiresAt.Before(summary.ValidUntil)) {
+			summary.ValidUntil = state.ExpiresAt
+		}
+		switch state.Status {
+		case StatusWaiting:
+			summary.Waiting++
+		case StatusBlocked:
+			summary.Blocked++
+		default:
+			continue
+		}
+		if session := b.tmuxSession(state.ID); session != "" {
+			summary.Sessions[session]++
+		}
+	}
+	return summary, nil
+}
+
+// tmuxSession returns the tmux session recorded in the context of id, if any.
+func (b *Beacon) tmuxSession(id string) string {
+	if b.contextStore == nil {
+		return ""
+	}
+	data, err := b.contextStore.Read(id)
+	if err != nil {
+		return ""
+	}
+	ctx, err := context.ParseTmuxContext(data)
+	if err != nil {
+		return ""
+	}
+	return ctx.SessionName
+}
+
+// cachedSummary is the on-disk form of a cached Summary.
+type cachedSummary struct {
+	Revision string  `json:"revision"`
+	Summary  Summary `json:"summary"`
+}
+
+// Revision returns the modification time of the base directory, which
+// changes whenever a state or context file is created, replaced or removed.
+func (s *FileStore) Revision() (string, error) {
+	info, err := os.Stat(s.baseDir)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(info.ModTime().UnixNano(), 10), nil
+}
+
+// LoadSummary returns the cached summary if it was saved at revision.
+func (s *FileStore) LoadSummary(revision string) (Summary, bool) {
+	data, err := os.ReadFile(s.summaryPath())
+	if err != nil {
+		return Summary{}, false
+	}
+	var cached cachedSummary
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Revision != revision {
+		return Summary{}, false
+	}
+	return cached.Summary, true
+}
+
+// SaveSummary caches summary for revision. Nothing is cached while the
+// revision is too recent to tell later changes apart from it.
+func (s *FileStore) SaveSummary(revision string, summary Summary) error {
+	mtime, err := strconv.ParseInt(revision, 10, 64)
+	if err != nil {
+		return err
+	}
+	if s.now().Sub(time.Unix(0, mtime)) < racyRevisionAge {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.summaryPath()), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cachedSummary{Revision: revision, Summary: summary})
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(s.summaryPath(), data, 0644)
+}
+
+func (s *FileStore) summaryPath() string {
+	return filepath.Join(s.baseDir, summaryCacheDir, "summary.json")
+}
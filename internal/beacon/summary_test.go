@@ -0,0 +1,140 @@
+package beacon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+func TestBeacon_Summary(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := newMockStore()
+	store.states["a"] = State{ID: "a", Status: StatusWaiting}
+	store.states["b"] = State{ID: "b", Status: StatusBlocked, ExpiresAt: now.Add(time.Hour)}
+	store.states["c"] = State{ID: "c", Status: StatusWaiting, ExpiresAt: now.Add(time.Minute)}
+	store.states["d"] = State{ID: "d", Status: StatusRunning, ExpiresAt: now.Add(30 * time.Second)}
+	store.states["e"] = State{ID: "e", Status: StatusWaiting}
+	store.states["expired"] = State{ID: "expired", Status: StatusWaiting, ExpiresAt: now}
+	contextStore := newMockContextStore()
+	contextStore.contexts["a"] = &context.TmuxContext{SessionName: "api", PaneID: "%1"}
+	contextStore.contexts["b"] = &context.TmuxContext{SessionName: "api", PaneID: "%2"}
+	contextStore.contexts["c"] = &context.TmuxContext{SessionName: "web", PaneID: "%3"}
+	contextStore.contexts["d"] = &context.TmuxContext{SessionName: "web", PaneID: "%4"}
+	b := NewWithContextStore(store, contextStore)
+	b.now = func() time.Time { return now }
+
+	summary, err := b.Summary()
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if summary.Waiting != 3 || summary.Blocked != 1 || summary.Total() != 4 {
+		t.Errorf("Summary() counts = %+v", summary)
+	}
+	if len(summary.Sessions) != 2 || summary.Sessions["api"] != 2 || summary.Sessions["web"] != 1 {
+		t.Errorf("Summary() sessions = %v", summary.Sessions)
+	}
+	if !summary.ValidUntil.Equal(now.Add(30 * time.Second)) {
+		t.Errorf("Summary() ValidUntil = %v, want %v", summary.ValidUntil, now.Add(30*time.Second))
+	}
+}
+
+func TestBeacon_Summary_Cache(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+	b := New(store)
+	// Age the base directory past the racy window after each change.
+	settle := func() {
+		t.Helper()
+		old := time.Now().Add(-time.Minute)
+		if err := os.Chtimes(tmpDir, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.Write("a", "first"); err != nil {
+		t.Fatal(err)
+	}
+	settle()
+	summary, err := b.Summary()
+	if err != nil || summary.Waiting != 1 {
+		t.Fatalf("Summary() = %+v, %v, want 1 waiting", summary, err)
+	}
+	// Creating the cache directory changed the base directory once.
+	settle()
+	if _, err := b.Summary(); err != nil {
+		t.Fatal(err)
+	}
+
+	cachePath := filepath.Join(tmpDir, summaryCacheDir, "summary.json")
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("summary was not cached: %v", err)
+	}
+
+	// A cached summary is used while the base directory is unchanged.
+	revision, err := store.Revision()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveSummary(revision, Summary{Waiting: 42}); err != nil {
+		t.Fatal(err)
+	}
+	if summary, _ := b.Summary(); summary.Waiting != 42 {
+		t.Errorf("Summary() = %+v, want cached summary", summary)
+	}
+
+	// Any change to the base directory invalidates it.
+	if err := store.Write("b", "second"); err != nil {
+		t.Fatal(err)
+	}
+	if summary, _ := b.Summary(); summary.Waiting != 2 {
+		t.Errorf("Summary() after write = %+v, want 2 waiting", summary)
+	}
+}
+
+func TestBeacon_Summary_CacheExpiry(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+	b := New(store)
+	if err := os.MkdirAll(filepath.Join(tmpDir, summaryCacheDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(tmpDir, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	revision, err := store.Revision()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := Summary{Waiting: 1, ValidUntil: time.Now().Add(-time.Second)}
+	if err := store.SaveSummary(revision, stale); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := b.Summary()
+	if err != nil {
+		t.Fatalf("Summary() error = %v", err)
+	}
+	if summary.Waiting != 0 {
+		t.Errorf("Summary() = %+v, want recomputed summary after expiry", summary)
+	}
+}
+
+func TestFileStore_SaveSummary_RacyRevision(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+
+	revision, err := store.Revision()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveSummary(revision, Summary{Waiting: 1}); err != nil {
+		t.Fatalf("SaveSummary() error = %v", err)
+	}
+	if _, ok := store.LoadSummary(revision); ok {
+		t.Error("LoadSummary() found a summary cached for a racy revision")
+	}
+}
@@ -0,0 +1,118 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+
+	"github.com/monochromegane/beacon/internal/storage"
+)
+
+// ErrWatchUnsupported is returned when the store cannot stream changes.
+var ErrWatchUnsupported = errors.New("store does not support watching")
+
+// EventType describes how a beacon changed.
+type EventType string
+
+const (
+	EventAdded   EventType = "added"
+	EventUpdated EventType = "updated"
+	EventRemoved EventType = "removed"
+)
+
+// Event is a change to a beacon state.
+// State holds the new state, or the last known state for removed beacons.
+type Event struct {
+	Type  EventType `json:"type"`
+	ID    string    `json:"id"`
+	State State     `json:"state"`
+}
+
+// Watcher is implemented by stores that can stream state changes.
+type Watcher interface {
+	Watch(ctx context.Context) (<-chan Event, error)
+}
+
+// Watch streams changes to beacon states from the underlying store.
+// Returns ErrWatchUnsupported if the store does not implement Watcher.
+func (b *Beacon) Watch(ctx context.Context) (<-chan Event, error) {
+	w, ok := b.store.(Watcher)
+	if !ok {
+		return nil, ErrWatchUnsupported
+	}
+	return w.Watch(ctx)
+}
+
+// Watch streams changes to the beacon states in the base directory.
+// Beacons that already exist are reported as added first.
+// The channel is closed when ctx is done or the directory is removed.
+func (s *FileStore) Watch(ctx context.Context) (<-chan Event, error) {
+	if err := os.MkdirAll(s.baseDir, 0755); err != nil {
+		return nil, err
+	}
+	changes, err := storage.WatchDir(ctx, s.baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		prev := map[string]State{}
+		for {
+			states, err := s.List()
+			if err != nil {
+				return
+			}
+			cur := make(map[string]State, len(states))
+			for _, state := range states {
+				cur[state.ID] = state
+			}
+			for _, event := range diffStates(prev, cur) {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+			prev = cur
+
+			select {
+			case _, ok := <-changes:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// diffStates returns the events that turn prev into cur.
+func diffStates(prev, cur map[string]State) []Event {
+	var events []Event
+	for id, state := range cur {
+		old, ok := prev[id]
+		switch {
+		case !ok:
+			events = append(events, Event{Type: EventAdded, ID: id, State: state})
+		case stateChanged(old, state):
+			events = append(events, Event{Type: EventUpdated, ID: id, State: state})
+		}
+	}
+	for id, state := range prev {
+		if _, ok := cur[id]; !ok {
+			events = append(events, Event{Type: EventRemoved, ID: id, State: state})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events
+}
+
+// stateChanged reports whether b is a newer revision of a.
+func stateChanged(a, b State) bool {
+	return a.EmitCount != b.EmitCount || !a.UpdatedAt.Equal(b.UpdatedAt) || a.Message != b.Message
+}
@@ -0,0 +1,103 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func nextEvent(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("Watch() channel closed unexpectedly")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch() no event received")
+	}
+	return Event{}
+}
+
+func TestFileStore_Watch(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileStoreWithDir(tmpDir)
+	store.Write("existing", "already there")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := store.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	event := nextEvent(t, events)
+	if event.Type != EventAdded || event.ID != "existing" {
+		t.Errorf("Watch() initial event = %+v, want added existing", event)
+	}
+
+	store.Write("test123", "first")
+	event = nextEvent(t, events)
+	if event.Type != EventAdded || event.ID != "test123" || event.State.Message != "first" {
+		t.Errorf("Watch() event = %+v, want added test123", event)
+	}
+
+	store.Write("test123", "second")
+	event = nextEvent(t, events)
+	if event.Type != EventUpdated || event.State.Message != "second" {
+		t.Errorf("Watch() event = %+v, want updated test123", event)
+	}
+
+	store.Delete("test123")
+	event = nextEvent(t, events)
+	if event.Type != EventRemoved || event.ID != "test123" || event.State.Message != "second" {
+		t.Errorf("Watch() event = %+v, want removed test123", event)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestDiffStates(t *testing.T) {
+	now := time.Now()
+	prev := map[string]State{
+		"same":    {ID: "same", EmitCount: 1, UpdatedAt: now},
+		"changed": {ID: "changed", EmitCount: 1, UpdatedAt: now},
+		"gone":    {ID: "gone", EmitCount: 1, UpdatedAt: now},
+	}
+	cur := map[string]State{
+		"same":    {ID: "same", EmitCount: 1, UpdatedAt: now},
+		"changed": {ID: "changed", EmitCount: 2, UpdatedAt: now.Add(time.Second)},
+		"new":     {ID: "new", EmitCount: 1, UpdatedAt: now},
+	}
+
+	events := diffStates(prev, cur)
+	want := []struct {
+		typ EventType
+		id  string
+	}{
+		{EventUpdated, "changed"},
+		{EventRemoved, "gone"},
+		{EventAdded, "new"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("diffStates() len = %d, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		if events[i].Type != w.typ || events[i].ID != w.id {
+			t.Errorf("diffStates()[%d] = %s %s, want %s %s", i, events[i].Type, events[i].ID, w.typ, w.id)
+		}
+	}
+}
+
+func TestBeacon_Watch_Unsupported(t *testing.T) {
+	b := New(newMockStore())
+
+	_, err := b.Watch(context.Background())
+	if !errors.Is(err, ErrWatchUnsupported) {
+		t.Errorf("Watch() error = %v, want ErrWatchUnsupported", err)
+	}
+}
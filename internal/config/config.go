@@ -0,0 +1,464 @@
+// Package config loads the beacon configuration file.
+//
+// The file is a small subset of TOML: [section] headers, key = value pairs
+// and # comments. Values are quoted strings, booleans or integers; durations
+// are written as strings such as "5s".
+//
+//	[storage]
+//	base_dir = "~/.local/state/beacon"
+//	profile = "work"
+//
+//	[id]
+//	env = "TMUX_PANE"
+//
+//	[emit]
+//	context = "tmux"
+//
+//	[list]
+//	format = "json"
+//
+//	[tmux]
+//	indicator = true
+//	window_status_style = "fg=black,bg=yellow"
+//
+//	[notify]
+//	desktop = true
+//
+//	[hooks]
+//	on_emit = "/home/me/bin/beacon-emitted"
+//	timeout = "10s"
+//
+//	[webhook.slack]
+//	url = "https://hooks.slack.com/services/..."
+//	format = "slack"
+//
+//	[webhook.slack.headers]
+//	X-Source = "beacon"
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnknownKey is returned for keys that are not part of Config.
+var ErrUnknownKey = errors.New("unknown config key")
+
+// Config holds the settings read from the configuration file.
+// Each field is addressed by its dotted key, such as "tmux.indicator".
+// Settings that have a flag or environment variable are defaults which
+// both override.
+type Config struct {
+	Storage StorageConfig `config:"storage"`
+	ID      IDConfig      `config:"id"`
+	Emit    EmitConfig    `config:"emit"`
+	List    ListConfig    `config:"list"`
+	Tmux    TmuxConfig    `config:"tmux"`
+	Notify  NotifyConfig  `config:"notify"`
+	// Webhook holds the destinations events are posted to, one [webhook.NAME]
+	// section each.
+	Webhook map[string]WebhookConfig `config:"webhook"`
+	Hooks   HooksConfig              `config:"hooks"`
+}
+
+// StorageConfig controls where beacons are kept.
+type StorageConfig struct {
+	// BaseDir replaces $XDG_CACHE_HOME/beacon. A leading ~ is the home directory.
+	BaseDir string `config:"base_dir"`
+	// Profile selects a separate namespace of beacons in the base directory.
+	Profile string `config:"profile"`
+}
+
+// IDConfig controls how beacon IDs are found when --id is omitted.
+type IDConfig struct {
+	// Env names an environment variable holding the ID, such as TMUX_PANE.
+	// It is consulted after BEACON_ID.
+	Env string `config:"env"`
+}
+
+// EmitConfig holds defaults for beacon emit.
+type EmitConfig struct {
+	// Context is the context type recorded with every emit.
+	Context string `config:"context" values:"none,tmux"`
+}
+
+// ListConfig holds defaults for beacon list.
+type ListConfig struct {
+	// Format is the output format used without --format.
+	Format string `config:"format" values:"table,json,ndjson,tsv"`
+}
+
+// TmuxConfig controls the tmux indicators shown for beacons with tmux context.
+type TmuxConfig struct {
+	// Indicator sets the @beacon_status user option on the pane and window of
+	// a beacon while it is active.
+	Indicator bool `config:"indicator"`
+	// WindowStatusStyle is applied as window-status-style of the window while
+	// a beacon in it is active, then the previous style is restored. Empty
+	// leaves the style alone.
+	WindowStatusStyle string `config:"window_status_style"`
+	// DisplayMessage shows the beacon message on attached clients when emitted.
+	DisplayMessage bool `config:"display_message"`
+}
+
+// NotifyConfig controls the notifications sent when beacons are emitted.
+type NotifyConfig struct {
+	// Desktop shows a desktop notification through the freedesktop.org
+	// notification service, falling back to notify-send.
+	Desktop bool `config:"desktop"`
+}
+
+// HooksConfig names executables run on emits and silences. Each receives the
+// event JSON on stdin and BEACON_* environment variables.
+type HooksConfig struct {
+	// OnEmit runs when a beacon that was not active is emitted.
+	OnEmit string `config:"on_emit"`
+	// OnUpdate runs when an active beacon is emitted again.
+	OnUpdate string `config:"on_update"`
+	// OnSilence runs when a beacon is silenced or garbage-collected.
+	OnSilence string `config:"on_silence"`
+	// Timeout bounds each run; zero means 10s.
+	Timeout time.Duration `config:"timeout"`
+}
+
+// WebhookConfig is a URL that emits and silences are posted to. A section
+// starts from the json format, a 5s timeout and 2 retries.
+type WebhookConfig struct {
+	URL string `config:"url"`
+	// Format selects a built-in body template: json (the event itself),
+	// slack, discord or ntfy.
+	Format string `config:"format"`
+	// Template is a Go text/template for the body, overriding Format.
+	Template string `config:"template"`
+	// Headers are added to each request; values are templates, too.
+	Headers map[string]string `config:"headers"`
+	Timeout time.Duration     `config:"timeout"`
+	// Retries is how often a failed request is repeated, with backoff. All
+	// webhooks are posted to at once and give up retrying after 15s.
+	Retries int `config:"retries"`
+}
+
+func (w *WebhookConfig) setDefaults() {
+	w.Format = "json"
+	w.Timeout = 5 * time.Second
+	w.Retries = 2
+}
+
+// ResolvePath returns the path of the configuration file.
+func ResolvePath() (string, error) {
+	if xdgConfig := os.Getenv("XDG_CONFIG_HOME"); xdgConfig != "" {
+		return filepath.Join(xdgConfig, "beacon", "config.toml"), nil
+	}
+	userConfig, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(userConfig, "beacon", "config.toml"), nil
+}
+
+// Load reads the configuration file. A missing file yields the zero Config.
+func Load() (*Config, error) {
+	path, err := ResolvePath()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads the configuration file at path. A missing file yields the zero Config.
+func LoadFile(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cfg, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse reads configuration from r. Errors are prefixed with the line number.
+func Parse(r io.Reader) (*Config, error) {
+	cfg := &Config{}
+	section := ""
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%d: invalid section header %q", n, line)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%d: expected key = value, got %q", n, line)
+		}
+		key = strings.TrimSpace(key)
+		if section != "" {
+			key = section + "." + key
+		}
+		value, err := parseValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%d: %s: %w", n, key, err)
+		}
+		if err := cfg.Set(key, value); err != nil {
+			return nil, fmt.Errorf("%d: %w", n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// stripComment removes a trailing # comment that is not inside a quoted string.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseValue converts a TOML value to its string form: quoted strings are
+// unquoted and booleans and integers are kept as written.
+func parseValue(raw string) (string, error) {
+	switch {
+	case raw == "":
+		return "", errors.New("missing value")
+	case strings.HasPrefix(raw, `"`):
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", raw)
+		}
+		return s, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") || strings.Contains(raw[1:len(raw)-1], "'") {
+			return "", fmt.Errorf("invalid string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw == "true" || raw == "false":
+		return raw, nil
+	}
+	if _, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return raw, nil
+	}
+	return "", fmt.Errorf("invalid value %s (strings must be quoted)", raw)
+}
+
+// Keys returns all configuration keys in file order. Keys inside named
+// sections are shown with a placeholder, such as "webhook.<name>.url".
+func Keys() []string {
+	var keys []string
+	walk(reflect.TypeOf(Config{}), "", func(key string) {
+		keys = append(keys, key)
+	})
+	return keys
+}
+
+// Get returns the value of key in its string form.
+func (c *Config) Get(key string) (string, error) {
+	field, _, ok := lookup(reflect.ValueOf(c).Elem(), key)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, key)
+	}
+	switch {
+	case field.Type() == durationType:
+		return time.Duration(field.Int()).String(), nil
+	case field.Kind() == reflect.Bool:
+		return strconv.FormatBool(field.Bool()), nil
+	case field.Kind() == reflect.Int:
+		return strconv.FormatInt(field.Int(), 10), nil
+	}
+	return field.String(), nil
+}
+
+// Set parses value and assigns it to key.
+func (c *Config) Set(key string, value string) error {
+	field, commit, ok := lookup(reflect.ValueOf(c).Elem(), key)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, key)
+	}
+	switch {
+	case field.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected a duration such as 5s, got %q", key, value)
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected true or false, got %q", key, value)
+		}
+		field.SetBool(b)
+	case field.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected an integer, got %q", key, value)
+		}
+		field.SetInt(int64(n))
+	default:
+		if err := checkValue(key, value); err != nil {
+			return err
+		}
+		field.SetString(value)
+	}
+	commit()
+	return nil
+}
+
+// checkValue rejects a value not listed in the values tag of the field
+// addressed by key. Empty values are always accepted.
+func checkValue(key, value string) error {
+	allowed := allowedValues(reflect.TypeOf(Config{}), key)
+	if value == "" || allowed == nil || slices.Contains(allowed, value) {
+		return nil
+	}
+	return fmt.Errorf("%s: expected one of %s, got %q", key, strings.Join(allowed, ", "), value)
+}
+
+// allowedValues returns the values tag of the field addressed by key below
+// the struct type t, or nil if any value is allowed.
+func allowedValues(t reflect.Type, key string) []string {
+	name, rest, _ := strings.Cut(key, ".")
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if tag, ok := f.Tag.Lookup("config"); !ok || tag != name {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.Struct:
+			return allowedValues(f.Type, rest)
+		case reflect.Map:
+			if f.Type.Elem().Kind() != reflect.Struct {
+				return nil
+			}
+			_, rest, _ = strings.Cut(rest, ".")
+			return allowedValues(f.Type.Elem(), rest)
+		}
+		if values, ok := f.Tag.Lookup("values"); ok {
+			return strings.Split(values, ",")
+		}
+		return nil
+	}
+	return nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// defaulter is implemented by named sections with non-zero defaults.
+type defaulter interface {
+	setDefaults()
+}
+
+// lookup returns the settable field addressed by key below the struct v.
+// Fields inside map entries are set on a copy, which commit stores back.
+// Looking up an entry that does not exist yields its default value.
+func lookup(v reflect.Value, key string) (field reflect.Value, commit func(), ok bool) {
+	name, rest, _ := strings.Cut(key, ".")
+	t := v.Type()
+	for i := range t.NumField() {
+		if tag, ok := t.Field(i).Tag.Lookup("config"); !ok || tag != name {
+			continue
+		}
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Struct:
+			if rest == "" {
+				return reflect.Value{}, nil, false
+			}
+			return lookup(field, rest)
+		case reflect.Map:
+			entryName, rest, _ := strings.Cut(rest, ".")
+			if entryName == "" {
+				return reflect.Value{}, nil, false
+			}
+			entry := reflect.New(field.Type().Elem()).Elem()
+			if existing := field.MapIndex(reflect.ValueOf(entryName)); existing.IsValid() {
+				entry.Set(existing)
+			} else if d, ok := entry.Addr().Interface().(defaulter); ok {
+				d.setDefaults()
+			}
+			store := func() {
+				if field.IsNil() {
+					field.Set(reflect.MakeMap(field.Type()))
+				}
+				field.SetMapIndex(reflect.ValueOf(entryName), entry)
+			}
+			if entry.Kind() != reflect.Struct {
+				return entry, store, rest == ""
+			}
+			if rest == "" {
+				return reflect.Value{}, nil, false
+			}
+			inner, commit, ok := lookup(entry, rest)
+			if !ok {
+				return reflect.Value{}, nil, false
+			}
+			return inner, func() { commit(); store() }, true
+		default:
+			return field, func() {}, rest == ""
+		}
+	}
+	return reflect.Value{}, nil, false
+}
+
+// walk calls fn with the dotted key of every leaf field of the struct type t.
+func walk(t reflect.Type, prefix string, fn func(key string)) {
+	for i := range t.NumField() {
+		name, ok := t.Field(i).Tag.Lookup("config")
+		if !ok {
+			continue
+		}
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		switch ft := t.Field(i).Type; ft.Kind() {
+		case reflect.Struct:
+			walk(ft, key, fn)
+		case reflect.Map:
+			if ft.Elem().Kind() == reflect.Struct {
+				walk(ft.Elem(), key+".<name>", fn)
+			} else {
+				fn(key + ".<key>")
+			}
+		default:
+			fn(key)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	input := `# beacon configuration
+
+[tmux]
+indicator = true # mark panes
+window_status_style = "fg=black,bg=yellow#1"
+display_message = false
+`
+	cfg, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := TmuxConfig{Indicator: true, WindowStatusStyle: "fg=black,bg=yellow#1"}
+	if cfg.Tmux != want {
+		t.Errorf("Parse() = %+v, want %+v", cfg.Tmux, want)
+	}
+}
+
+func TestParse_DottedKeys(t *testing.T) {
+	cfg, err := Parse(strings.NewReader("tmux.indicator = true\ntmux.window_status_style = 'bg=red'\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !cfg.Tmux.Indicator || cfg.Tmux.WindowStatusStyle != "bg=red" {
+		t.Errorf("Parse() = %+v", cfg.Tmux)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"[tmux]\nindicator = yes\n", "2: tmux.indicator: invalid value yes (strings must be quoted)"},
+		{"\n\n[tmux]\ncolour = \"red\"\n", "4: unknown config key: tmux.colour"},
+		{"[tmux\n", "1: invalid section header"},
+		{"[tmux]\nindicator\n", "2: expected key = value"},
+		{"[tmux]\nwindow_status_style = \"bg=red\n", "2: tmux.window_status_style: invalid string"},
+		{"[tmux]\nindicator = \"maybe\"\n", "2: tmux.indicator: expected true or false"},
+		{"[tmux]\nindicator =\n", "2: tmux.indicator: missing value"},
+		{"[emit]\ncontext = \"screen\"\n", `2: emit.context: expected one of none, tmux, got "screen"`},
+		{"\n[list]\nformat = \"yaml\"\n", `3: list.format: expected one of table, json, ndjson, tsv, got "yaml"`},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.input))
+		if err == nil {
+			t.Errorf("Parse(%q) expected error, got nil", tt.input)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %q, want prefix %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestParse_Webhooks(t *testing.T) {
+	input := `[webhook.slack]
+url = "https://hooks.example.com/a"
+format = "slack"
+
+[webhook.slack.headers]
+Authorization = "Bearer token"
+X-Source = "beacon"
+
+[webhook.ntfy]
+url = "https://ntfy.example.com/agents"
+timeout = "2s"
+retries = 0
+`
+	cfg, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := map[string]WebhookConfig{
+		"slack": {
+			URL:     "https://hooks.example.com/a",
+			Format:  "slack",
+			Headers: map[string]string{"Authorization": "Bearer token", "X-Source": "beacon"},
+			Timeout: 5 * time.Second,
+			Retries: 2,
+		},
+		"ntfy": {
+			URL:     "https://ntfy.example.com/agents",
+			Format:  "json",
+			Timeout: 2 * time.Second,
+		},
+	}
+	if !reflect.DeepEqual(cfg.Webhook, want) {
+		t.Errorf("Parse() webhooks = %+v, want %+v", cfg.Webhook, want)
+	}
+}
+
+func TestParse_WebhookErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"[webhook.a]\ntimeout = \"soon\"\n", "2: webhook.a.timeout: expected a duration"},
+		{"[webhook.a]\nmethod = \"PUT\"\n", "2: unknown config key: webhook.a.method"},
+		{"[webhook]\nurl = \"x\"\n", "2: unknown config key: webhook.url"},
+		{"[webhook.a.headers.b]\nc = \"x\"\n", "2: unknown config key: webhook.a.headers.b.c"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.input))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want prefix %q", tt.input, err, tt.want)
+		}
+	}
+}
+
+func TestConfig_GetSet(t *testing.T) {
+	cfg := &Config{}
+	if err := cfg.Set("tmux.indicator", "true"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, err := cfg.Get("tmux.indicator"); err != nil || got != "true" {
+		t.Errorf("Get() = %q, %v, want true", got, err)
+	}
+	if err := cfg.Set("tmux.nope", "1"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Set() error = %v, want ErrUnknownKey", err)
+	}
+	if got, err := cfg.Get("webhook.new.timeout"); err != nil || got != "5s" {
+		t.Errorf("Get() of a missing section = %q, %v, want the default 5s", got, err)
+	}
+	if err := cfg.Set("webhook.new.headers.X-Token", "abc"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, err := cfg.Get("webhook.new.headers.X-Token"); err != nil || got != "abc" {
+		t.Errorf("Get() = %q, %v, want abc", got, err)
+	}
+	if _, err := cfg.Get("tmux"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Get() of a section error = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	got := strings.Join(Keys(), " ")
+	want := "storage.base_dir storage.profile id.env emit.context list.format " +
+		"tmux.indicator tmux.window_status_style tmux.display_message notify.desktop " +
+		"webhook.<name>.url webhook.<name>.format webhook.<name>.template webhook.<name>.headers.<key> " +
+		"webhook.<name>.timeout webhook.<name>.retries " +
+		"hooks.on_emit hooks.on_update hooks.on_silence hooks.timeout"
+	if got != want {
+		t.Errorf("Keys() = %s", got)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() without file error = %v", err)
+	}
+	if !reflect.DeepEqual(*cfg, Config{}) {
+		t.Errorf("Load() without file = %+v, want zero Config", cfg)
+	}
+
+	path := filepath.Join(dir, "beacon", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("[tmux]\nindicator = true\nbogus = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load()
+	if err == nil || !strings.HasPrefix(err.Error(), path+":3: unknown config key: tmux.bogus") {
+		t.Errorf("Load() error = %v, want it to name %s:3", err, path)
+	}
+}
+
+func TestParse_Defaults(t *testing.T) {
+	input := `[storage]
+base_dir = "~/beacons"
+
+[id]
+env = "TMUX_PANE"
+
+[emit]
+context = "tmux"
+
+[list]
+format = "ndjson"
+`
+	cfg, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := Config{
+		Storage: StorageConfig{BaseDir: "~/beacons"},
+		ID:      IDConfig{Env: "TMUX_PANE"},
+		Emit:    EmitConfig{Context: "tmux"},
+		List:    ListConfig{Format: "ndjson"},
+	}
+	if !reflect.DeepEqual(*cfg, want) {
+		t.Errorf("Parse() = %+v, want %+v", *cfg, want)
+	}
+}
+
+func TestSetFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "beacon", "config.toml")
+
+	steps := []struct {
+		key, value string
+		want       string
+	}{
+		{"emit.context", "tmux", "[emit]\ncontext = \"tmux\"\n"},
+		{"tmux.indicator", "true", "[emit]\ncontext = \"tmux\"\n\n[tmux]\nindicator = true\n"},
+		{"emit.context", "none", "[emit]\ncontext = \"none\"\n\n[tmux]\nindicator = true\n"},
+		{"list.format", "json", "[emit]\ncontext = \"none\"\n\n[tmux]\nindicator = true\n\n[list]\nformat = \"json\"\n"},
+	}
+	for _, step := range steps {
+		if err := SetFile(path, step.key, step.value); err != nil {
+			t.Fatalf("SetFile(%s, %s) error = %v", step.key, step.value, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != step.want {
+			t.Errorf("after SetFile(%s, %s) file =\n%s\nwant\n%s", step.key, step.value, data, step.want)
+		}
+	}
+}
+
+func TestSetFile_KeepsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	input := `# beacon configuration
+[tmux]
+  indicator = false # off for now
+display_message = true
+
+[webhook.chat]
+url = "https://example.com"
+`
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetFile(path, "tmux.indicator", "true"); err != nil {
+		t.Fatalf("SetFile() error = %v", err)
+	}
+	if err := SetFile(path, "tmux.window_status_style", "bg=red"); err != nil {
+		t.Fatalf("SetFile() error = %v", err)
+	}
+	if err := SetFile(path, "webhook.chat.timeout", "2s"); err != nil {
+		t.Fatalf("SetFile() error = %v", err)
+	}
+	want := `# beacon configuration
+[tmux]
+  indicator = true
+display_message = true
+window_status_style = "bg=red"
+
+[webhook.chat]
+url = "https://example.com"
+timeout = "2s"
+`
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Errorf("file =\n%s\nwant\n%s", data, want)
+	}
+	cfg, err := LoadFile(path)
+	if err != nil || !cfg.Tmux.Indicator || cfg.Webhook["chat"].Timeout != 2*time.Second {
+		t.Errorf("LoadFile() = %+v, %v", cfg, err)
+	}
+}
+
+func TestSetFile_Errors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := SetFile(path, "list.format", "yaml"); err == nil || !strings.Contains(err.Error(), "expected one of") {
+		t.Errorf("SetFile() error = %v, want the allowed values", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("SetFile() wrote the file despite an invalid value")
+	}
+
+	broken := "[tmux]\nindicator = yes\n"
+	if err := os.WriteFile(path, []byte(broken), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetFile(path, "tmux.display_message", "true"); err == nil || !strings.HasPrefix(err.Error(), path+":2:") {
+		t.Errorf("SetFile() error = %v, want the broken line", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != broken {
+		t.Errorf("SetFile() changed a broken file to %q", data)
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/monochromegane/beacon/internal/storage"
+)
+
+// SetFile assigns value to key in the configuration file at path, creating
+// the file if needed. The value is checked like Set. Other lines, including
+// comments, are kept; a file that does not parse is left alone.
+func SetFile(path, key, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	cfg, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%s:%w", path, err)
+	}
+	if err := cfg.Set(key, value); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, edit(data, key, formatValue(key, value)), 0644)
+}
+
+// formatValue writes value in TOML form for key: booleans and integers as
+// is, everything else as a quoted string.
+func formatValue(key, value string) string {
+	field, _, _ := lookup(reflect.ValueOf(&Config{}).Elem(), key)
+	if field.Type() != durationType && (field.Kind() == reflect.Bool || field.Kind() == reflect.Int) {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+// edit replaces the last line setting key in data, or adds one to the end of
+// its section, adding the section if there is none.
+func edit(data []byte, key, value string) []byte {
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	dot := strings.LastIndex(key, ".")
+	sectionName, name := key[:dot], key[dot+1:]
+
+	match, insertAt := -1, -1
+	section := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(stripComment(line))
+		if trimmed == "" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") {
+			section = strings.TrimSpace(strings.Trim(trimmed, "[]"))
+			if section == sectionName {
+				insertAt = i + 1
+			}
+			continue
+		}
+		k, _, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			continue
+		}
+		full := strings.TrimSpace(k)
+		if section != "" {
+			full = section + "." + full
+		}
+		if full == key {
+			match = i
+		}
+		if section == sectionName {
+			insertAt = i + 1
+		}
+	}
+
+	switch {
+	case match >= 0:
+		indent := lines[match][:len(lines[match])-len(strings.TrimLeft(lines[match], " \t"))]
+		k, _, _ := strings.Cut(strings.TrimSpace(lines[match]), "=")
+		lines[match] = indent + strings.TrimSpace(k) + " = " + value
+	case insertAt >= 0:
+		lines = append(lines[:insertAt], append([]string{name + " = " + value}, lines[insertAt:]...)...)
+	default:
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+sectionName+"]", name+" = "+value)
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
@@ -0,0 +1,90 @@
+package context
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrNotAgentContext is returned when stored context data does not describe an agent session.
+var ErrNotAgentContext = errors.New("not an agent context")
+
+// ErrNoTerminal is returned when jumping to an agent session whose terminal location is unknown.
+var ErrNoTerminal = errors.New("no terminal location recorded")
+
+// AgentContext describes a coding agent session reported through its hooks:
+// the working directory and transcript of the session and, if the agent
+// runs in tmux, its pane. The pane fields are stored inline, so code that
+// only understands tmux contexts still finds them.
+type AgentContext struct {
+	// Agent names the agent, such as "claude".
+	Agent          string `json:"agent"`
+	Cwd            string `json:"cwd,omitempty"`
+	TranscriptPath string `json:"transcript_path,omitempty"`
+	*TmuxContext
+}
+
+// Type returns the context type identifier.
+func (c *AgentContext) Type() string {
+	return "agent"
+}
+
+// ToJSON serializes the context to JSON.
+func (c *AgentContext) ToJSON() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+// Parts returns the tmux pane of the session, if any.
+func (c *AgentContext) Parts() []Context {
+	if c.TmuxContext == nil {
+		return nil
+	}
+	return []Context{c.TmuxContext}
+}
+
+// Location returns the tmux pane of the session, or else its working directory.
+func (c *AgentContext) Location() string {
+	if c.TmuxContext != nil {
+		return c.TmuxContext.Location()
+	}
+	return c.Cwd
+}
+
+// Jump switches to the tmux pane of the session.
+// Returns ErrNoTerminal if the session does not run in tmux.
+func (c *AgentContext) Jump(executor CommandExecutor) error {
+	if c.TmuxContext == nil {
+		return ErrNoTerminal
+	}
+	return c.TmuxContext.Jump(executor)
+}
+
+// Indicate marks the tmux pane of the session, if any.
+func (c *AgentContext) Indicate(executor CommandExecutor, opts IndicatorOptions, ind Indication) error {
+	if c.TmuxContext == nil {
+		return nil
+	}
+	return c.TmuxContext.Indicate(executor, opts, ind)
+}
+
+// ClearIndication unmarks the tmux pane of the session, if any.
+func (c *AgentContext) ClearIndication(executor CommandExecutor, opts IndicatorOptions) error {
+	if c.TmuxContext == nil {
+		return nil
+	}
+	return c.TmuxContext.ClearIndication(executor, opts)
+}
+
+// ParseAgentContext decodes an AgentContext from its JSON representation.
+func ParseAgentContext(data []byte) (*AgentContext, error) {
+	var ctx AgentContext
+	if err := json.Unmarshal(data, &ctx); err != nil {
+		return nil, err
+	}
+	if ctx.Agent == "" {
+		return nil, ErrNotAgentContext
+	}
+	if ctx.TmuxContext != nil && ctx.PaneID == "" {
+		ctx.TmuxContext = nil
+	}
+	return &ctx, nil
+}
@@ -1,5 +1,16 @@
 package context
 
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTargetGone is returned when the location recorded in a context no longer exists.
+var ErrTargetGone = errors.New("jump target no longer exists")
+
+// ErrUnknownContext is returned when stored context data matches no known context type.
+var ErrUnknownContext = errors.New("unknown context format")
+
 // Context represents contextual information that can be serialized to JSON.
 type Context interface {
 	Type() string
@@ -10,3 +21,60 @@ type Context interface {
 type Provider interface {
 	GetContext() (Context, error)
 }
+
+// Jumper is implemented by contexts that can bring the user to the
+// terminal location of the agent.
+type Jumper interface {
+	Jump(executor CommandExecutor) error
+}
+
+// Locator is implemented by contexts that can describe their location in a
+// short human-readable form, such as "main:1.0" for a tmux pane.
+type Locator interface {
+	Location() string
+}
+
+// Composite is implemented by contexts that contain contexts of other
+// types, such as an agent session running in a tmux pane.
+type Composite interface {
+	Parts() []Context
+}
+
+// Indication describes a beacon shown by an Indicator.
+type Indication struct {
+	ID      string
+	Status  string
+	Message string
+}
+
+// IndicatorOptions controls how an Indicator marks the location of a beacon.
+type IndicatorOptions struct {
+	// WindowStatusStyle is applied to the window in the tmux status line. Empty leaves it alone.
+	WindowStatusStyle string
+	// DisplayMessage shows the message of the beacon on attached clients.
+	DisplayMessage bool
+}
+
+// Indicator is implemented by contexts that can visually mark the terminal
+// location of the agent while its beacon is active.
+type Indicator interface {
+	Indicate(executor CommandExecutor, opts IndicatorOptions, ind Indication) error
+	ClearIndication(executor CommandExecutor, opts IndicatorOptions) error
+}
+
+// decoders parse stored context data, one per context type. Agent contexts
+// come first since they may carry the fields of a tmux context.
+var decoders = []func(data []byte) (Context, error){
+	func(data []byte) (Context, error) { return ParseAgentContext(data) },
+	func(data []byte) (Context, error) { return ParseTmuxContext(data) },
+}
+
+// Decode returns the Context described by stored JSON data.
+func Decode(data []byte) (Context, error) {
+	for _, decode := range decoders {
+		if ctx, err := decode(data); err == nil {
+			return ctx, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownContext, data)
+}
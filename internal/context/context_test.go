@@ -0,0 +1,64 @@
+package context
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecode_Tmux(t *testing.T) {
+	ctx, err := Decode([]byte(`{"session_name":"main","window_index":0,"pane_index":1,"pane_id":"%2"}`))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if ctx.Type() != "tmux" {
+		t.Errorf("Decode() type = %q, want %q", ctx.Type(), "tmux")
+	}
+	if _, ok := ctx.(Jumper); !ok {
+		t.Error("Decode() tmux context does not implement Jumper")
+	}
+}
+
+func TestDecode_Unknown(t *testing.T) {
+	if _, err := Decode([]byte(`{"foo":"bar"}`)); !errors.Is(err, ErrUnknownContext) {
+		t.Errorf("Decode() error = %v, want ErrUnknownContext", err)
+	}
+}
+
+func TestDecode_Agent(t *testing.T) {
+	ctx, err := Decode([]byte(`{"agent":"claude","cwd":"/src/web","transcript_path":"/t.jsonl","session_name":"main","window_index":2,"pane_index":0,"pane_id":"%5"}`))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	agent, ok := ctx.(*AgentContext)
+	if !ok {
+		t.Fatalf("Decode() = %T, want *AgentContext", ctx)
+	}
+	if agent.Cwd != "/src/web" || agent.TranscriptPath != "/t.jsonl" || agent.Location() != "main:2.0" {
+		t.Errorf("Decode() = %+v", agent)
+	}
+	// Code that only knows tmux contexts still finds the pane.
+	if tmux, err := ParseTmuxContext([]byte(`{"agent":"claude","pane_id":"%5"}`)); err != nil || tmux.PaneID != "%5" {
+		t.Errorf("ParseTmuxContext() of an agent context = %+v, %v", tmux, err)
+	}
+}
+
+func TestAgentContext_WithoutTmux(t *testing.T) {
+	ctx, err := Decode([]byte(`{"agent":"codex","cwd":"/src/api"}`))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	agent := ctx.(*AgentContext)
+	if agent.TmuxContext != nil || agent.Location() != "/src/api" {
+		t.Errorf("Decode() = %+v, want no tmux pane", agent)
+	}
+	if err := agent.Jump(nil); !errors.Is(err, ErrNoTerminal) {
+		t.Errorf("Jump() error = %v, want ErrNoTerminal", err)
+	}
+	if err := agent.Indicate(nil, IndicatorOptions{}, Indication{}); err != nil {
+		t.Errorf("Indicate() error = %v", err)
+	}
+	data, err := agent.ToJSON()
+	if err != nil || string(data) != `{"agent":"codex","cwd":"/src/api"}` {
+		t.Errorf("ToJSON() = %s, %v", data, err)
+	}
+}
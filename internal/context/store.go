@@ -21,44 +21,89 @@ type FileContextStore struct {
 
 // NewFileContextStore creates a new FileContextStore with the resolved base directory.
 func NewFileContextStore() (*FileContextStore, error) {
-	baseDir, err := storage.ResolveBaseDir()
+	return NewFileContextStoreAt(storage.Location{})
+}
+
+// NewFileContextStoreAt creates a new FileContextStore for the directory of
+// loc, the same one NewFileStoreAt uses for the beacons.
+func NewFileContextStoreAt(loc storage.Location) (*FileContextStore, error) {
+	baseDir, err := loc.Dir()
 	if err != nil {
 		return nil, err
 	}
 	return &FileContextStore{baseDir: baseDir}, nil
 }
 
-// NewFileContextStoreWithDir creates a new FileContextStore with a custom base directory (for testing).
+// NewFileContextStoreWithDir creates a new FileContextStore with a custom base directory.
 func NewFileContextStoreWithDir(baseDir string) *FileContextStore {
 	return &FileContextStore{baseDir: baseDir}
 }
 
 // Write saves the context as a JSON file for the given ID.
+// The file is replaced atomically under the base directory lock, and a file
+// written under the unencoded name of the ID is removed.
 func (s *FileContextStore) Write(id string, ctx Context) error {
-	if err := os.MkdirAll(s.baseDir, 0755); err != nil {
+	path, legacyPath, err := s.paths(id)
+	if err != nil {
 		return err
 	}
 	data, err := ctx.ToJSON()
 	if err != nil {
 		return err
 	}
-	path := filepath.Join(s.baseDir, id+".json")
-	return os.WriteFile(path, data, 0644)
+	lock, err := storage.LockDir(s.baseDir)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	if err := storage.WriteFileAtomic(path, data, 0644); err != nil {
+		return err
+	}
+	return storage.RemoveFiles(legacyPath)
 }
 
-// Delete removes the context JSON file for the given ID.
+// Delete removes the context JSON file for the given ID, including one
+// written under its unencoded name.
 // Returns nil if the file does not exist (idempotent).
 func (s *FileContextStore) Delete(id string) error {
-	path := filepath.Join(s.baseDir, id+".json")
-	err := os.Remove(path)
-	if os.IsNotExist(err) {
-		return nil
+	path, legacyPath, err := s.paths(id)
+	if err != nil {
+		return err
+	}
+	lock, err := storage.LockDir(s.baseDir)
+	if err != nil {
+		return err
 	}
-	return err
+	defer lock.Unlock()
+
+	return storage.RemoveFiles(path, legacyPath)
 }
 
-// Read returns the raw JSON content of the context file for the given ID.
+// Read returns the raw JSON content of the context file for the given ID,
+// falling back to a file written under its unencoded name.
 func (s *FileContextStore) Read(id string) ([]byte, error) {
-	path := filepath.Join(s.baseDir, id+".json")
-	return os.ReadFile(path)
+	path, legacyPath, err := s.paths(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && legacyPath != "" {
+		return os.ReadFile(legacyPath)
+	}
+	return data, err
+}
+
+// paths returns the context file path for the given ID and, if the ID has
+// one, the path it had before IDs were encoded.
+func (s *FileContextStore) paths(id string) (string, string, error) {
+	parsed, err := storage.ParseID(id)
+	if err != nil {
+		return "", "", err
+	}
+	var legacyPath string
+	if name, ok := parsed.LegacyFilename(); ok {
+		legacyPath = filepath.Join(s.baseDir, name+".json")
+	}
+	return filepath.Join(s.baseDir, parsed.Filename()+".json"), legacyPath, nil
 }
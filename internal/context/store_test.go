@@ -1,9 +1,16 @@
 package context
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sync"
 	"testing"
+
+	"github.com/monochromegane/beacon/internal/storage"
 )
 
 func TestFileContextStore_Write(t *testing.T) {
@@ -108,6 +115,24 @@ func TestFileContextStore_Read(t *testing.T) {
 	}
 }
 
+func TestFileContextStore_LegacyFilename(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileContextStoreWithDir(tmpDir)
+	legacyPath := filepath.Join(tmpDir, "my.session.json")
+	os.WriteFile(legacyPath, []byte(`{"pane_id":"%1"}`), 0644)
+
+	data, err := store.Read("my.session")
+	if err != nil || string(data) != `{"pane_id":"%1"}` {
+		t.Fatalf("Read() = %q, %v, want the legacy file", data, err)
+	}
+	if err := store.Delete("my.session"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Errorf("Delete() left the legacy file: %v", err)
+	}
+}
+
 func TestFileContextStore_Read_NonExistent(t *testing.T) {
 	tmpDir := t.TempDir()
 	store := NewFileContextStoreWithDir(tmpDir)
@@ -120,3 +145,149 @@ func TestFileContextStore_Read_NonExistent(t *testing.T) {
 		t.Errorf("Read() error = %v, want os.IsNotExist error", err)
 	}
 }
+
+func TestFileContextStore_Write_Concurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileContextStoreWithDir(tmpDir)
+
+	const writers, writes = 20, 25
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			data, err := store.Read("shared")
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				t.Errorf("Read() error = %v", err)
+				return
+			}
+			var ctx TmuxContext
+			if err := json.Unmarshal(data, &ctx); err != nil {
+				t.Errorf("Read() observed partial file %q: %v", data, err)
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < writes; j++ {
+				ctx := &TmuxContext{SessionName: fmt.Sprintf("session-%d", i), WindowIndex: j, PaneID: "%0"}
+				if err := store.Write("shared", ctx); err != nil {
+					t.Errorf("Write() error = %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(done)
+	readers.Wait()
+
+	entries, _ := os.ReadDir(tmpDir)
+	for _, entry := range entries {
+		if entry.Name() != "shared.json" && entry.Name() != ".lock" {
+			t.Errorf("Write() left unexpected file %q", entry.Name())
+		}
+	}
+}
+
+// TestHelperProcess is not a real test. It is executed as a child process by
+// TestFileContextStore_Write_ConcurrentProcesses to write to a shared store.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("BEACON_TEST_HELPER_PROCESS") != "1" {
+		return
+	}
+	store := NewFileContextStoreWithDir(os.Getenv("BEACON_TEST_DIR"))
+	for j := 0; j < 25; j++ {
+		ctx := &TmuxContext{SessionName: fmt.Sprintf("pid-%d", os.Getpid()), WindowIndex: j, PaneID: "%0"}
+		if err := store.Write("shared", ctx); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(0)
+}
+
+func TestFileContextStore_Write_ConcurrentProcesses(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewFileContextStoreWithDir(tmpDir)
+
+	var cmds []*exec.Cmd
+	for i := 0; i < 8; i++ {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+		cmd.Env = append(os.Environ(), "BEACON_TEST_HELPER_PROCESS=1", "BEACON_TEST_DIR="+tmpDir)
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("helper process error = %v", err)
+		}
+	}
+
+	data, err := store.Read("shared")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	var ctx TmuxContext
+	if err := json.Unmarshal(data, &ctx); err != nil {
+		t.Errorf("Read() = %q, not valid JSON: %v", data, err)
+	}
+	if ctx.WindowIndex != 24 {
+		t.Errorf("Read() window_index = %d, want 24 (last write of a writer)", ctx.WindowIndex)
+	}
+}
+
+func TestFileContextStore_EncodesUnsafeID(t *testing.T) {
+	tmpDir := t.TempDir()
+	baseDir := filepath.Join(tmpDir, "beacon")
+	store := NewFileContextStoreWithDir(baseDir)
+
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%0"}
+	if err := store.Write("../escape", ctx); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "escape.json")); !os.IsNotExist(err) {
+		t.Error("Write() created a file outside the base directory")
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "%2E%2E%2Fescape.json")); err != nil {
+		t.Errorf("Write() encoded file missing: %v", err)
+	}
+
+	if _, err := store.Read("../escape"); err != nil {
+		t.Errorf("Read() error = %v", err)
+	}
+	if err := store.Delete("../escape"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+}
+
+func TestFileContextStore_InvalidID(t *testing.T) {
+	store := NewFileContextStoreWithDir(t.TempDir())
+
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%0"}
+	if err := store.Write("", ctx); !errors.Is(err, storage.ErrInvalidID) {
+		t.Errorf("Write() error = %v, want ErrInvalidID", err)
+	}
+	if _, err := store.Read("bad\nid"); !errors.Is(err, storage.ErrInvalidID) {
+		t.Errorf("Read() error = %v, want ErrInvalidID", err)
+	}
+	if err := store.Delete(""); !errors.Is(err, storage.ErrInvalidID) {
+		t.Errorf("Delete() error = %v, want ErrInvalidID", err)
+	}
+}
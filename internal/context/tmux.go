@@ -3,6 +3,7 @@ package context
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"strconv"
@@ -12,12 +13,18 @@ import (
 // ErrNotInTmux is returned when tmux context is requested outside of a tmux session.
 var ErrNotInTmux = errors.New("not running inside tmux")
 
+// ErrNotTmuxContext is returned when stored context data does not describe a tmux pane.
+var ErrNotTmuxContext = errors.New("not a tmux context")
+
 // TmuxContext represents tmux session/window/pane information.
 type TmuxContext struct {
 	SessionName string `json:"session_name"`
 	WindowIndex int    `json:"window_index"`
 	PaneIndex   int    `json:"pane_index"`
 	PaneID      string `json:"pane_id"`
+	// WindowID is the stable ID of the window, such as @3. Contexts
+	// recorded by older versions do not have it.
+	WindowID string `json:"window_id,omitempty"`
 }
 
 // Type returns the context type identifier.
@@ -30,6 +37,194 @@ func (c *TmuxContext) ToJSON() ([]byte, error) {
 	return json.Marshal(c)
 }
 
+// Location returns the pane as session:window.pane.
+func (c *TmuxContext) Location() string {
+	return fmt.Sprintf("%s:%d.%d", c.SessionName, c.WindowIndex, c.PaneIndex)
+}
+
+// Jump switches the tmux client to the session, window and pane of the
+// recorded pane. The pane ID is the target at every step, as it stays the
+// same when the window is moved or the session renamed.
+// Returns ErrTargetGone if the pane no longer exists.
+func (c *TmuxContext) Jump(executor CommandExecutor) error {
+	if _, err := executor.Execute("tmux", "display-message", "-p", "-t", c.PaneID, "#{pane_id}"); err != nil {
+		if paneMissing(err) {
+			return fmt.Errorf("%w: tmux pane %s in session %s", ErrTargetGone, c.PaneID, c.SessionName)
+		}
+		return fmt.Errorf("tmux display-message: %w", err)
+	}
+	return run(executor, [][]string{
+		{"switch-client", "-t", c.PaneID},
+		{"select-window", "-t", c.PaneID},
+		{"select-pane", "-t", c.PaneID},
+	})
+}
+
+// StatusOption is the tmux user option holding the status of the beacon
+// in a pane and its window, for use in formats such as window-status-format.
+const StatusOption = "@beacon_status"
+
+// savedStyleOption holds the window-status-style a window had before
+// Indicate replaced it, so that ClearIndication can put it back.
+const savedStyleOption = "@beacon_saved_style"
+
+// window returns the tmux target of the window of the pane. Contexts
+// recorded before the window ID was kept address it through the pane.
+func (c *TmuxContext) window() string {
+	if c.WindowID != "" {
+		return c.WindowID
+	}
+	return c.PaneID
+}
+
+// Indicate sets StatusOption on the pane and its window, optionally styles
+// the window in the status line and shows the message on attached clients.
+// A style set on the window beforehand is saved for ClearIndication.
+func (c *TmuxContext) Indicate(executor CommandExecutor, opts IndicatorOptions, ind Indication) error {
+	commands := [][]string{
+		{"set-option", "-p", "-t", c.PaneID, StatusOption, ind.Status},
+		{"set-option", "-w", "-t", c.window(), StatusOption, ind.Status},
+	}
+	if err := run(executor, commands); err != nil {
+		return err
+	}
+	if opts.WindowStatusStyle != "" {
+		if err := c.setWindowStyle(executor, opts.WindowStatusStyle); err != nil {
+			return err
+		}
+	}
+	if opts.DisplayMessage {
+		return c.displayMessage(executor, ind)
+	}
+	return nil
+}
+
+// setWindowStyle sets window-status-style on the window, first saving a
+// style the user set there.
+func (c *TmuxContext) setWindowStyle(executor CommandExecutor, style string) error {
+	current, err := showOption(executor, "-w", c.window(), "window-status-style")
+	if err != nil {
+		return err
+	}
+	var commands [][]string
+	// The style is already ours when another beacon in the window set it.
+	if current != "" && current != style {
+		commands = append(commands, []string{"set-option", "-w", "-t", c.window(), savedStyleOption, current})
+	}
+	commands = append(commands, []string{"set-option", "-w", "-t", c.window(), "window-status-style", style})
+	return run(executor, commands)
+}
+
+// displayMessage shows the beacon on every attached client.
+func (c *TmuxContext) displayMessage(executor CommandExecutor, ind Indication) error {
+	output, err := executor.Execute("tmux", "list-clients", "-F", "#{client_name}")
+	if err != nil {
+		return fmt.Errorf("tmux list-clients: %w", err)
+	}
+	// Escape '#' so the message is not expanded as a tmux format.
+	text := strings.ReplaceAll(fmt.Sprintf("beacon %s (%s:%d): %s", ind.ID, c.SessionName, c.WindowIndex, ind.Message), "#", "##")
+	for _, client := range strings.Fields(string(output)) {
+		if _, err := executor.Execute("tmux", "display-message", "-c", client, text); err != nil {
+			return fmt.Errorf("tmux display-message: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClearIndication unsets the status of the pane. The window keeps the
+// status of another pane with an active beacon, if any. Otherwise its status
+// is unset and its style is restored to the one saved by Indicate, or unset
+// so that it is inherited again. A pane that is gone leaves only its window
+// to clear, and a window that is gone leaves nothing.
+func (c *TmuxContext) ClearIndication(executor CommandExecutor, opts IndicatorOptions) error {
+	err := run(executor, [][]string{{"set-option", "-p", "-u", "-t", c.PaneID, StatusOption}})
+	if err != nil && !targetGone(err) {
+		return err
+	}
+	status, err := c.windowStatus(executor)
+	if targetGone(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if status != "" {
+		return run(executor, [][]string{{"set-option", "-w", "-t", c.window(), StatusOption, status}})
+	}
+
+	if err := run(executor, [][]string{{"set-option", "-w", "-u", "-t", c.window(), StatusOption}}); err != nil {
+		return err
+	}
+	if opts.WindowStatusStyle == "" {
+		return nil
+	}
+	saved, err := showOption(executor, "-w", c.window(), savedStyleOption)
+	if err != nil {
+		return err
+	}
+	if saved == "" {
+		return run(executor, [][]string{{"set-option", "-w", "-u", "-t", c.window(), "window-status-style"}})
+	}
+	return run(executor, [][]string{
+		{"set-option", "-w", "-t", c.window(), "window-status-style", saved},
+		{"set-option", "-w", "-u", "-t", c.window(), savedStyleOption},
+	})
+}
+
+// windowStatus returns the status of another pane in the window of the pane
+// that still has a beacon, or "" if there is none.
+func (c *TmuxContext) windowStatus(executor CommandExecutor) (string, error) {
+	output, err := executor.Execute("tmux", "list-panes", "-t", c.window(), "-F", "#{pane_id}")
+	if err != nil {
+		return "", fmt.Errorf("tmux list-panes: %w", err)
+	}
+	for _, pane := range strings.Fields(string(output)) {
+		if pane == c.PaneID {
+			continue
+		}
+		status, err := showOption(executor, "-p", pane, StatusOption)
+		if err != nil {
+			return "", err
+		}
+		if status != "" {
+			return status, nil
+		}
+	}
+	return "", nil
+}
+
+// showOption returns the value of an option set on a pane or window,
+// selected by scope "-p" or "-w". Inherited values are not reported.
+func showOption(executor CommandExecutor, scope, target, name string) (string, error) {
+	output, err := executor.Execute("tmux", "show-options", scope, "-q", "-v", "-t", target, name)
+	if err != nil {
+		return "", fmt.Errorf("tmux show-options: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// run executes tmux commands in order, stopping at the first failure.
+func run(executor CommandExecutor, commands [][]string) error {
+	for _, args := range commands {
+		if _, err := executor.Execute("tmux", args...); err != nil {
+			return fmt.Errorf("tmux %s: %w", args[0], err)
+		}
+	}
+	return nil
+}
+
+// ParseTmuxContext decodes a TmuxContext from its JSON representation.
+func ParseTmuxContext(data []byte) (*TmuxContext, error) {
+	var ctx TmuxContext
+	if err := json.Unmarshal(data, &ctx); err != nil {
+		return nil, err
+	}
+	if ctx.PaneID == "" {
+		return nil, ErrNotTmuxContext
+	}
+	return &ctx, nil
+}
+
 // TmuxProvider obtains tmux context information.
 type TmuxProvider struct {
 	executor CommandExecutor
@@ -43,9 +238,17 @@ type CommandExecutor interface {
 // DefaultExecutor is the default command executor using os/exec.
 type DefaultExecutor struct{}
 
-// Execute runs the command and returns its output.
+// Execute runs the command and returns its output. The error of a command
+// that fails includes what it wrote to stderr.
 func (e *DefaultExecutor) Execute(name string, args ...string) ([]byte, error) {
-	return exec.Command(name, args...).Output()
+	output, err := exec.Command(name, args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if stderr := strings.TrimSpace(string(exitErr.Stderr)); stderr != "" {
+			return output, fmt.Errorf("%w: %s", err, stderr)
+		}
+	}
+	return output, err
 }
 
 // NewTmuxProvider creates a new TmuxProvider with the default executor.
@@ -64,13 +267,13 @@ func (p *TmuxProvider) GetContext() (Context, error) {
 		return nil, ErrNotInTmux
 	}
 
-	output, err := p.executor.Execute("tmux", "display-message", "-p", "#{session_name}\t#{window_index}\t#{pane_index}\t#{pane_id}")
+	output, err := p.executor.Execute("tmux", "display-message", "-p", "#{session_name}\t#{window_index}\t#{pane_index}\t#{pane_id}\t#{window_id}")
 	if err != nil {
 		return nil, err
 	}
 
 	parts := strings.Split(strings.TrimSpace(string(output)), "\t")
-	if len(parts) != 4 {
+	if len(parts) != 5 {
 		return nil, errors.New("unexpected tmux output format")
 	}
 
@@ -89,5 +292,50 @@ func (p *TmuxProvider) GetContext() (Context, error) {
 		WindowIndex: windowIndex,
 		PaneIndex:   paneIndex,
 		PaneID:      parts[3],
+		WindowID:    parts[4],
 	}, nil
 }
+
+// ListPanes returns the IDs of all panes on the tmux server. There are none
+// when no server is running.
+func (p *TmuxProvider) ListPanes() (map[string]bool, error) {
+	output, err := p.executor.Execute("tmux", "list-panes", "-a", "-F", "#{pane_id}")
+	if err != nil {
+		if noServer(err) {
+			return map[string]bool{}, nil
+		}
+		return nil, err
+	}
+
+	panes := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		if paneID := strings.TrimSpace(line); paneID != "" {
+			panes[paneID] = true
+		}
+	}
+	return panes, nil
+}
+
+// targetGone reports whether a tmux command failed because its target pane
+// or window no longer exists, or the whole server has exited.
+func targetGone(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return paneMissing(err) || strings.Contains(msg, "no such window") || strings.Contains(msg, "can't find window") || noServer(err)
+}
+
+// paneMissing reports whether a tmux command failed because its target pane
+// does not exist.
+func paneMissing(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "can't find pane") || strings.Contains(msg, "no such pane")
+}
+
+// noServer reports whether a tmux command failed because no server is
+// running, as tmux says when its socket is missing.
+func noServer(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "no server running") || strings.Contains(msg, "error connecting to")
+}
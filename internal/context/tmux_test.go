@@ -3,6 +3,7 @@ package context
 import (
 	"errors"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -22,6 +23,13 @@ func TestTmuxContext_Type(t *testing.T) {
 	}
 }
 
+func TestTmuxContext_Location(t *testing.T) {
+	ctx := &TmuxContext{SessionName: "main", WindowIndex: 2, PaneIndex: 1, PaneID: "%5"}
+	if ctx.Location() != "main:2.1" {
+		t.Errorf("Location() = %q, want %q", ctx.Location(), "main:2.1")
+	}
+}
+
 func TestTmuxContext_ToJSON(t *testing.T) {
 	ctx := &TmuxContext{
 		SessionName: "main",
@@ -69,7 +77,7 @@ func TestTmuxProvider_GetContext_Success(t *testing.T) {
 	}()
 
 	executor := &mockExecutor{
-		output: []byte("main\t0\t1\t%2\n"),
+		output: []byte("main\t0\t1\t%2\t@1\n"),
 	}
 	provider := NewTmuxProviderWithExecutor(executor)
 
@@ -95,6 +103,9 @@ func TestTmuxProvider_GetContext_Success(t *testing.T) {
 	if tmuxCtx.PaneID != "%2" {
 		t.Errorf("PaneID = %q, want %q", tmuxCtx.PaneID, "%2")
 	}
+	if tmuxCtx.WindowID != "@1" {
+		t.Errorf("WindowID = %q, want %q", tmuxCtx.WindowID, "@1")
+	}
 }
 
 func TestTmuxProvider_GetContext_CommandError(t *testing.T) {
@@ -135,8 +146,8 @@ func TestTmuxProvider_GetContext_InvalidOutput(t *testing.T) {
 		output string
 	}{
 		{"too few parts", "main\t0\t1"},
-		{"invalid window index", "main\tabc\t1\t%2"},
-		{"invalid pane index", "main\t0\tabc\t%2"},
+		{"invalid window index", "main\tabc\t1\t%2\t@1"},
+		{"invalid pane index", "main\t0\tabc\t%2\t@1"},
 	}
 
 	for _, tt := range tests {
@@ -153,3 +164,351 @@ func TestTmuxProvider_GetContext_InvalidOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTmuxContext(t *testing.T) {
+	ctx, err := ParseTmuxContext([]byte(`{"session_name":"main","window_index":0,"pane_index":1,"pane_id":"%2"}`))
+	if err != nil {
+		t.Fatalf("ParseTmuxContext() error = %v", err)
+	}
+	want := TmuxContext{SessionName: "main", WindowIndex: 0, PaneIndex: 1, PaneID: "%2"}
+	if *ctx != want {
+		t.Errorf("ParseTmuxContext() = %+v, want %+v", *ctx, want)
+	}
+
+	if _, err := ParseTmuxContext([]byte(`{"cwd":"/tmp"}`)); !errors.Is(err, ErrNotTmuxContext) {
+		t.Errorf("ParseTmuxContext() error = %v, want ErrNotTmuxContext", err)
+	}
+	if _, err := ParseTmuxContext([]byte(`not json`)); err == nil {
+		t.Error("ParseTmuxContext() expected error for invalid JSON, got nil")
+	}
+}
+
+// recordingExecutor records the commands it executes.
+type recordingExecutor struct {
+	output []byte
+	// outputs maps a whole command line to its output, overriding output.
+	outputs map[string]string
+	err     error
+	// errs maps a whole command line to its error, overriding err.
+	errs  map[string]error
+	calls [][]string
+}
+
+func (r *recordingExecutor) Execute(name string, args ...string) ([]byte, error) {
+	call := append([]string{name}, args...)
+	r.calls = append(r.calls, call)
+	if err, ok := r.errs[strings.Join(call, " ")]; ok {
+		return nil, err
+	}
+	if output, ok := r.outputs[strings.Join(call, " ")]; ok {
+		return []byte(output), r.err
+	}
+	return r.output, r.err
+}
+
+func TestTmuxProvider_ListPanes(t *testing.T) {
+	executor := &recordingExecutor{output: []byte("%0\n%1\n%5\n")}
+	provider := NewTmuxProviderWithExecutor(executor)
+
+	panes, err := provider.ListPanes()
+	if err != nil {
+		t.Fatalf("ListPanes() error = %v", err)
+	}
+	if len(panes) != 3 || !panes["%0"] || !panes["%1"] || !panes["%5"] {
+		t.Errorf("ListPanes() = %v, want %%0 %%1 %%5", panes)
+	}
+
+	want := []string{"tmux", "list-panes", "-a", "-F", "#{pane_id}"}
+	if len(executor.calls) != 1 || strings.Join(executor.calls[0], " ") != strings.Join(want, " ") {
+		t.Errorf("ListPanes() executed %v, want %v", executor.calls, want)
+	}
+}
+
+func TestTmuxProvider_ListPanes_Error(t *testing.T) {
+	provider := NewTmuxProviderWithExecutor(&mockExecutor{err: errors.New("exit status 1: open terminal failed: not a terminal")})
+
+	if _, err := provider.ListPanes(); err == nil {
+		t.Error("ListPanes() expected error, got nil")
+	}
+}
+
+func TestTmuxProvider_ListPanes_NoServer(t *testing.T) {
+	for _, msg := range []string{
+		"exit status 1: no server running on /tmp/tmux-1000/default",
+		"exit status 1: error connecting to /tmp/notmux/tmux-1000/default (No such file or directory)",
+	} {
+		provider := NewTmuxProviderWithExecutor(&mockExecutor{err: errors.New(msg)})
+
+		panes, err := provider.ListPanes()
+		if err != nil {
+			t.Errorf("ListPanes() with %q error = %v", msg, err)
+		}
+		if len(panes) != 0 {
+			t.Errorf("ListPanes() with %q = %v, want none", msg, panes)
+		}
+	}
+}
+
+func TestDefaultExecutor_Stderr(t *testing.T) {
+	_, err := (&DefaultExecutor{}).Execute("sh", "-c", "echo 'no such pane' >&2; exit 1")
+	if err == nil || err.Error() != "exit status 1: no such pane" {
+		t.Errorf("Execute() error = %v, want exit status 1: no such pane", err)
+	}
+}
+
+func TestTmuxContext_Jump(t *testing.T) {
+	executor := &recordingExecutor{}
+	ctx := &TmuxContext{SessionName: "main", WindowIndex: 2, PaneIndex: 1, PaneID: "%5"}
+
+	if err := ctx.Jump(executor); err != nil {
+		t.Fatalf("Jump() error = %v", err)
+	}
+
+	want := []string{
+		"tmux display-message -p -t %5 #{pane_id}",
+		"tmux switch-client -t %5",
+		"tmux select-window -t %5",
+		"tmux select-pane -t %5",
+	}
+	if len(executor.calls) != len(want) {
+		t.Fatalf("Jump() executed %v, want %v", executor.calls, want)
+	}
+	for i, call := range executor.calls {
+		if got := strings.Join(call, " "); got != want[i] {
+			t.Errorf("Jump() call[%d] = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestTmuxContext_Jump_PaneGone(t *testing.T) {
+	executor := &recordingExecutor{err: errors.New("can't find pane: %5")}
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%5"}
+
+	err := ctx.Jump(executor)
+	if !errors.Is(err, ErrTargetGone) {
+		t.Errorf("Jump() error = %v, want ErrTargetGone", err)
+	}
+	if len(executor.calls) != 1 {
+		t.Errorf("Jump() executed %d commands after pane check failed, want 1", len(executor.calls))
+	}
+}
+
+func TestTmuxContext_Jump_Error(t *testing.T) {
+	executor := &recordingExecutor{err: errors.New("exit status 1: no server running on /tmp/tmux-1000/default")}
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%5"}
+
+	err := ctx.Jump(executor)
+	if err == nil || errors.Is(err, ErrTargetGone) {
+		t.Errorf("Jump() error = %v, want an error other than ErrTargetGone", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "no server running") {
+		t.Errorf("Jump() error = %v, want the tmux error", err)
+	}
+}
+
+func TestTmuxContext_Indicate(t *testing.T) {
+	executor := &recordingExecutor{outputs: map[string]string{
+		"tmux list-clients -F #{client_name}": "/dev/pts/1\n/dev/pts/2\n",
+	}}
+	ctx := &TmuxContext{SessionName: "main", WindowIndex: 2, PaneIndex: 1, PaneID: "%5"}
+
+	err := ctx.Indicate(executor, IndicatorOptions{WindowStatusStyle: "bg=yellow", DisplayMessage: true}, Indication{
+		ID:      "agent-1",
+		Status:  "waiting-for-input",
+		Message: "Approve #1?",
+	})
+	if err != nil {
+		t.Fatalf("Indicate() error = %v", err)
+	}
+
+	want := []string{
+		"tmux set-option -p -t %5 @beacon_status waiting-for-input",
+		"tmux set-option -w -t %5 @beacon_status waiting-for-input",
+		"tmux show-options -w -q -v -t %5 window-status-style",
+		"tmux set-option -w -t %5 window-status-style bg=yellow",
+		"tmux list-clients -F #{client_name}",
+		"tmux display-message -c /dev/pts/1 beacon agent-1 (main:2): Approve ##1?",
+		"tmux display-message -c /dev/pts/2 beacon agent-1 (main:2): Approve ##1?",
+	}
+	assertCalls(t, executor.calls, want)
+}
+
+func TestTmuxContext_Indicate_OptionOnly(t *testing.T) {
+	executor := &recordingExecutor{}
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%5"}
+
+	if err := ctx.Indicate(executor, IndicatorOptions{}, Indication{ID: "a", Status: "running"}); err != nil {
+		t.Fatalf("Indicate() error = %v", err)
+	}
+	want := []string{
+		"tmux set-option -p -t %5 @beacon_status running",
+		"tmux set-option -w -t %5 @beacon_status running",
+	}
+	assertCalls(t, executor.calls, want)
+}
+
+func TestTmuxContext_Indicate_Error(t *testing.T) {
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%5"}
+	err := ctx.Indicate(&recordingExecutor{err: errors.New("can't find pane")}, IndicatorOptions{}, Indication{ID: "a"})
+	if err == nil {
+		t.Error("Indicate() expected error, got nil")
+	}
+}
+
+func TestTmuxContext_Indicate_SavesWindowStyle(t *testing.T) {
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%5"}
+	opts := IndicatorOptions{WindowStatusStyle: "bg=yellow"}
+
+	executor := &recordingExecutor{outputs: map[string]string{
+		"tmux show-options -w -q -v -t %5 window-status-style": "fg=blue\n",
+	}}
+	if err := ctx.Indicate(executor, opts, Indication{ID: "a", Status: "running"}); err != nil {
+		t.Fatalf("Indicate() error = %v", err)
+	}
+	want := []string{
+		"tmux set-option -p -t %5 @beacon_status running",
+		"tmux set-option -w -t %5 @beacon_status running",
+		"tmux show-options -w -q -v -t %5 window-status-style",
+		"tmux set-option -w -t %5 @beacon_saved_style fg=blue",
+		"tmux set-option -w -t %5 window-status-style bg=yellow",
+	}
+	assertCalls(t, executor.calls, want)
+
+	// The style of an indicated window is ours and must not overwrite the saved one.
+	executor = &recordingExecutor{outputs: map[string]string{
+		"tmux show-options -w -q -v -t %5 window-status-style": "bg=yellow\n",
+	}}
+	if err := ctx.Indicate(executor, opts, Indication{ID: "a", Status: "running"}); err != nil {
+		t.Fatalf("Indicate() error = %v", err)
+	}
+	for _, call := range executor.calls {
+		if strings.Contains(strings.Join(call, " "), "@beacon_saved_style") {
+			t.Errorf("Indicate() on an indicated window executed %v", call)
+		}
+	}
+}
+
+func TestTmuxContext_ClearIndication(t *testing.T) {
+	executor := &recordingExecutor{outputs: map[string]string{
+		"tmux list-panes -t %5 -F #{pane_id}": "%4\n%5\n",
+	}}
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%5"}
+
+	if err := ctx.ClearIndication(executor, IndicatorOptions{WindowStatusStyle: "bg=yellow", DisplayMessage: true}); err != nil {
+		t.Fatalf("ClearIndication() error = %v", err)
+	}
+	want := []string{
+		"tmux set-option -p -u -t %5 @beacon_status",
+		"tmux list-panes -t %5 -F #{pane_id}",
+		"tmux show-options -p -q -v -t %4 @beacon_status",
+		"tmux set-option -w -u -t %5 @beacon_status",
+		"tmux show-options -w -q -v -t %5 @beacon_saved_style",
+		"tmux set-option -w -u -t %5 window-status-style",
+	}
+	assertCalls(t, executor.calls, want)
+}
+
+func TestTmuxContext_ClearIndication_RestoresWindowStyle(t *testing.T) {
+	executor := &recordingExecutor{outputs: map[string]string{
+		"tmux list-panes -t %5 -F #{pane_id}":                  "%5\n",
+		"tmux show-options -w -q -v -t %5 @beacon_saved_style": "fg=blue\n",
+	}}
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%5"}
+
+	if err := ctx.ClearIndication(executor, IndicatorOptions{WindowStatusStyle: "bg=yellow"}); err != nil {
+		t.Fatalf("ClearIndication() error = %v", err)
+	}
+	want := []string{
+		"tmux set-option -p -u -t %5 @beacon_status",
+		"tmux list-panes -t %5 -F #{pane_id}",
+		"tmux set-option -w -u -t %5 @beacon_status",
+		"tmux show-options -w -q -v -t %5 @beacon_saved_style",
+		"tmux set-option -w -t %5 window-status-style fg=blue",
+		"tmux set-option -w -u -t %5 @beacon_saved_style",
+	}
+	assertCalls(t, executor.calls, want)
+}
+
+func TestTmuxContext_ClearIndication_OtherBeaconInWindow(t *testing.T) {
+	executor := &recordingExecutor{outputs: map[string]string{
+		"tmux list-panes -t %5 -F #{pane_id}":             "%4\n%5\n%6\n",
+		"tmux show-options -p -q -v -t %6 @beacon_status": "blocked\n",
+	}}
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%5"}
+
+	if err := ctx.ClearIndication(executor, IndicatorOptions{WindowStatusStyle: "bg=yellow"}); err != nil {
+		t.Fatalf("ClearIndication() error = %v", err)
+	}
+	// The window keeps the style and shows the status of the remaining beacon.
+	want := []string{
+		"tmux set-option -p -u -t %5 @beacon_status",
+		"tmux list-panes -t %5 -F #{pane_id}",
+		"tmux show-options -p -q -v -t %4 @beacon_status",
+		"tmux show-options -p -q -v -t %6 @beacon_status",
+		"tmux set-option -w -t %5 @beacon_status blocked",
+	}
+	assertCalls(t, executor.calls, want)
+}
+
+func TestTmuxContext_ClearIndication_PaneGone(t *testing.T) {
+	executor := &recordingExecutor{
+		outputs: map[string]string{
+			"tmux list-panes -t @2 -F #{pane_id}":                  "%4\n",
+			"tmux show-options -w -q -v -t @2 @beacon_saved_style": "fg=blue\n",
+		},
+		errs: map[string]error{
+			"tmux set-option -p -u -t %5 @beacon_status": errors.New("exit status 1: no such pane: %5"),
+		},
+	}
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%5", WindowID: "@2"}
+
+	if err := ctx.ClearIndication(executor, IndicatorOptions{WindowStatusStyle: "bg=yellow"}); err != nil {
+		t.Fatalf("ClearIndication() error = %v", err)
+	}
+	want := []string{
+		"tmux set-option -p -u -t %5 @beacon_status",
+		"tmux list-panes -t @2 -F #{pane_id}",
+		"tmux show-options -p -q -v -t %4 @beacon_status",
+		"tmux set-option -w -u -t @2 @beacon_status",
+		"tmux show-options -w -q -v -t @2 @beacon_saved_style",
+		"tmux set-option -w -t @2 window-status-style fg=blue",
+		"tmux set-option -w -u -t @2 @beacon_saved_style",
+	}
+	assertCalls(t, executor.calls, want)
+}
+
+func TestTmuxContext_ClearIndication_WindowGone(t *testing.T) {
+	executor := &recordingExecutor{errs: map[string]error{
+		"tmux set-option -p -u -t %5 @beacon_status": errors.New("exit status 1: no such pane: %5"),
+		"tmux list-panes -t @2 -F #{pane_id}":        errors.New("exit status 1: can't find window: @2"),
+	}}
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%5", WindowID: "@2"}
+
+	if err := ctx.ClearIndication(executor, IndicatorOptions{WindowStatusStyle: "bg=yellow"}); err != nil {
+		t.Errorf("ClearIndication() error = %v", err)
+	}
+	if len(executor.calls) != 2 {
+		t.Errorf("ClearIndication() executed %v after the window was gone", executor.calls)
+	}
+}
+
+func TestTmuxContext_ClearIndication_Error(t *testing.T) {
+	executor := &recordingExecutor{err: errors.New("exit status 1: server exited unexpectedly")}
+	ctx := &TmuxContext{SessionName: "main", PaneID: "%5", WindowID: "@2"}
+
+	if err := ctx.ClearIndication(executor, IndicatorOptions{}); err == nil {
+		t.Error("ClearIndication() expected error, got nil")
+	}
+}
+
+func assertCalls(t *testing.T, calls [][]string, want []string) {
+	t.Helper()
+	if len(calls) != len(want) {
+		t.Fatalf("executed %d commands %v, want %d", len(calls), calls, len(want))
+	}
+	for i, call := range calls {
+		if got := strings.Join(call, " "); got != want[i] {
+			t.Errorf("command %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
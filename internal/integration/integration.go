@@ -0,0 +1,73 @@
+// Package integration renders the shell and tmux snippets printed by beacon init.
+package integration
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+//go:embed scripts/*.tmpl
+var scripts embed.FS
+
+// Shells lists the supported integration targets.
+var Shells = []string{"bash", "zsh", "fish", "tmux"}
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"sh":   shellQuote,
+	"fish": fishQuote,
+	"tmux": tmuxEscape,
+}).ParseFS(scripts, "scripts/*.tmpl"))
+
+// Script returns the integration snippet for shell, invoking beacon as bin.
+func Script(shell string, bin string) (string, error) {
+	tmpl := templates.Lookup(shell + ".tmpl")
+	if tmpl == nil {
+		return "", fmt.Errorf("unsupported shell: %s (supported: %s)", shell, strings.Join(Shells, ", "))
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct{ Bin string }{bin}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// isPlainWord reports whether s needs no quoting in any supported shell.
+func isPlainWord(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte("-_./+,:@%", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	if isPlainWord(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes s as a single fish word.
+func fishQuote(s string) string {
+	if isPlainWord(s) {
+		return s
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// tmuxEscape escapes s for use inside a double-quoted tmux string.
+func tmuxEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`).Replace(s)
+}
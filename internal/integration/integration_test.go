@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestScript_Golden(t *testing.T) {
+	for _, shell := range Shells {
+		t.Run(shell, func(t *testing.T) {
+			got, err := Script(shell, "beacon")
+			if err != nil {
+				t.Fatalf("Script(%q) error = %v", shell, err)
+			}
+
+			golden := filepath.Join("testdata", shell+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading golden file: %v (run go test -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("Script(%q) differs from %s:\n%s", shell, golden, got)
+			}
+		})
+	}
+}
+
+func TestScript_Unsupported(t *testing.T) {
+	if _, err := Script("powershell", "beacon"); err == nil {
+		t.Error("Script() expected error for unsupported shell, got nil")
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in, sh, fish, tmux string
+	}{
+		{"beacon", "beacon", "beacon", "beacon"},
+		{"/usr/local/bin/beacon", "/usr/local/bin/beacon", "/usr/local/bin/beacon", "/usr/local/bin/beacon"},
+		{"/opt/my tools/beacon", "'/opt/my tools/beacon'", "'/opt/my tools/beacon'", "/opt/my tools/beacon"},
+		{`/it's/be"a\con$`, `'/it'\''s/be"a\con$'`, `'/it\'s/be"a\\con$'`, `/it's/be\"a\\con\$`},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.sh {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.sh)
+		}
+		if got := fishQuote(tt.in); got != tt.fish {
+			t.Errorf("fishQuote(%q) = %s, want %s", tt.in, got, tt.fish)
+		}
+		if got := tmuxEscape(tt.in); got != tt.tmux {
+			t.Errorf("tmuxEscape(%q) = %s, want %s", tt.in, got, tt.tmux)
+		}
+	}
+}
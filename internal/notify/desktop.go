@@ -0,0 +1,206 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+	"github.com/monochromegane/beacon/internal/process"
+	"github.com/monochromegane/beacon/internal/storage"
+)
+
+// Spawner starts a background process that waits for an action on
+// notification notificationID of beacon id, and returns that process.
+type Spawner func(id string, notificationID uint32) (process.Process, error)
+
+// record is what Desktop remembers about the notification of a beacon.
+type record struct {
+	NotificationID uint32          `json:"notification_id"`
+	Listener       process.Process `json:"listener,omitzero"`
+}
+
+// Desktop is a beacon.Notifier showing a desktop notification per beacon.
+// Emitting a beacon again replaces its notification, and silencing it or
+// setting it running closes the notification.
+//
+// For beacons whose context can be jumped to, such as a tmux pane, the
+// notification carries a focus action. The action is handled by a process
+// started with the Spawner, since the emitting process exits right away.
+//
+// When the session bus cannot be reached, notify-send is run instead,
+// without actions.
+type Desktop struct {
+	dir       string
+	dial      Dialer
+	executor  context.CommandExecutor
+	spawn     Spawner
+	processes beacon.ProcessChecker
+}
+
+// NewDesktop creates a Desktop that keeps its notification IDs in dir.
+// spawn may be nil to show notifications without actions.
+func NewDesktop(dir string, executor context.CommandExecutor, spawn Spawner) *Desktop {
+	return &Desktop{
+		dir:       dir,
+		dial:      DialSession,
+		executor:  executor,
+		spawn:     spawn,
+		processes: process.NewProcFS(),
+	}
+}
+
+// Notify shows, replaces or closes the notification of the beacon in event.
+func (d *Desktop) Notify(event beacon.Event, ctx context.Context) error {
+	rec, _ := d.load(event.ID)
+	if event.Type == beacon.EventRemoved || event.State.Status == beacon.StatusRunning {
+		return d.close(event.ID, rec)
+	}
+
+	n := Notification{
+		Summary:    fmt.Sprintf("%s: %s", event.ID, event.State.Status),
+		Body:       event.State.Message,
+		Urgency:    urgency(event.State.Status),
+		ReplacesID: rec.NotificationID,
+	}
+	_, canJump := ctx.(context.Jumper)
+	focus := canJump && d.spawn != nil
+	if focus {
+		n.Actions = []Action{{Key: ActionDefault, Label: "Focus"}, {Key: ActionFocus, Label: "Focus pane"}}
+	}
+
+	bus, err := d.dial()
+	if err != nil {
+		return d.notifySend(event.ID, rec, n)
+	}
+	defer bus.Close()
+	previous := rec.NotificationID
+	if rec.NotificationID, err = bus.Notify(n); err != nil {
+		return err
+	}
+	// A listener keeps waiting while its notification is replaced. A new
+	// ID means the old notification was closed, taking its listener along.
+	if focus && (rec.NotificationID != previous || !d.alive(rec.Listener)) {
+		if rec.Listener, err = d.spawn(event.ID, rec.NotificationID); err != nil {
+			d.save(event.ID, rec)
+			return err
+		}
+	}
+	return d.save(event.ID, rec)
+}
+
+// close closes the notification of a beacon, if one is shown.
+func (d *Desktop) close(id string, rec record) error {
+	if rec.NotificationID == 0 {
+		return nil
+	}
+	if err := d.remove(id); err != nil {
+		return err
+	}
+	bus, err := d.dial()
+	if err != nil {
+		// notify-send cannot close notifications; they time out on their own.
+		return nil
+	}
+	defer bus.Close()
+	return bus.CloseNotification(rec.NotificationID)
+}
+
+// notifySend shows n with notify-send, replacing the previous notification.
+func (d *Desktop) notifySend(id string, rec record, n Notification) error {
+	args := []string{"--app-name=" + appName, "--urgency=" + n.Urgency.String(), "--print-id"}
+	if n.ReplacesID != 0 {
+		args = append(args, "--replace-id="+strconv.FormatUint(uint64(n.ReplacesID), 10))
+	}
+	args = append(args, "--", n.Summary, n.Body)
+	out, err := d.executor.Execute("notify-send", args...)
+	if err != nil {
+		return fmt.Errorf("notify-send: %w", err)
+	}
+	notificationID, err := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 32)
+	if err != nil {
+		return nil
+	}
+	rec.NotificationID = uint32(notificationID)
+	return d.save(id, rec)
+}
+
+func (d *Desktop) alive(proc process.Process) bool {
+	if proc.PID == 0 {
+		return false
+	}
+	alive, err := d.processes.Alive(proc)
+	return err == nil && alive
+}
+
+func (d *Desktop) path(id string) (string, error) {
+	parsed, err := storage.ParseID(id)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d.dir, parsed.Filename()), nil
+}
+
+func (d *Desktop) load(id string) (record, error) {
+	path, err := d.path(id)
+	if err != nil {
+		return record{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return record{}, err
+	}
+	var rec record
+	err = json.Unmarshal(data, &rec)
+	return rec, err
+}
+
+func (d *Desktop) save(id string, rec record) error {
+	path, err := d.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return storage.WriteFileAtomic(path, data, 0644)
+}
+
+func (d *Desktop) remove(id string) error {
+	path, err := d.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// urgency maps the status of a beacon to a notification urgency.
+func urgency(status beacon.Status) Urgency {
+	switch status {
+	case beacon.StatusBlocked, beacon.StatusFailed:
+		return UrgencyCritical
+	}
+	return UrgencyNormal
+}
+
+// String returns the urgency name used by notify-send.
+func (u Urgency) String() string {
+	switch u {
+	case UrgencyLow:
+		return "low"
+	case UrgencyCritical:
+		return "critical"
+	}
+	return "normal"
+}
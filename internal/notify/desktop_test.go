@@ -0,0 +1,255 @@
+package notify
+
+import (
+	stdcontext "context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+	"github.com/monochromegane/beacon/internal/process"
+)
+
+// fakeBus is an in-process notification service.
+type fakeBus struct {
+	nextID   uint32
+	notified []Notification
+	closed   []uint32
+	signals  chan Signal
+	err      error
+}
+
+func newFakeBus() *fakeBus {
+	return &fakeBus{nextID: 1, signals: make(chan Signal, 8)}
+}
+
+func (b *fakeBus) Notify(n Notification) (uint32, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	b.notified = append(b.notified, n)
+	if n.ReplacesID != 0 {
+		return n.ReplacesID, nil
+	}
+	b.nextID++
+	return b.nextID - 1, nil
+}
+
+func (b *fakeBus) CloseNotification(id uint32) error {
+	b.closed = append(b.closed, id)
+	return nil
+}
+
+func (b *fakeBus) Signals() (<-chan Signal, error) {
+	return b.signals, nil
+}
+
+func (b *fakeBus) Close() error {
+	return nil
+}
+
+type fakeExecutor struct {
+	calls []string
+	out   string
+}
+
+func (e *fakeExecutor) Execute(name string, args ...string) ([]byte, error) {
+	e.calls = append(e.calls, name+" "+strings.Join(args, " "))
+	return []byte(e.out), nil
+}
+
+type fakeProcesses map[int]bool
+
+func (p fakeProcesses) Alive(proc process.Process) (bool, error) {
+	return p[proc.PID], nil
+}
+
+func newTestDesktop(t *testing.T, bus *fakeBus) (*Desktop, *[]uint32) {
+	t.Helper()
+	var spawned []uint32
+	spawn := func(id string, notificationID uint32) (process.Process, error) {
+		spawned = append(spawned, notificationID)
+		return process.Process{PID: 100 + len(spawned)}, nil
+	}
+	d := NewDesktop(t.TempDir(), &fakeExecutor{}, spawn)
+	d.dial = func() (Bus, error) { return bus, nil }
+	d.processes = fakeProcesses{}
+	return d, &spawned
+}
+
+func event(eventType beacon.EventType, id string, status beacon.Status, message string) beacon.Event {
+	return beacon.Event{Type: eventType, ID: id, State: beacon.State{ID: id, Status: status, Message: message}}
+}
+
+func TestDesktop_ReplacesNotification(t *testing.T) {
+	bus := newFakeBus()
+	d, _ := newTestDesktop(t, bus)
+
+	if err := d.Notify(event(beacon.EventAdded, "api", beacon.StatusWaiting, "approve?"), nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if err := d.Notify(event(beacon.EventUpdated, "api", beacon.StatusBlocked, "stuck"), nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if err := d.Notify(event(beacon.EventAdded, "web", beacon.StatusWaiting, "hi"), nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if len(bus.notified) != 3 {
+		t.Fatalf("notified %d times, want 3", len(bus.notified))
+	}
+	first, second, other := bus.notified[0], bus.notified[1], bus.notified[2]
+	if first.ReplacesID != 0 || first.Summary != "api: waiting-for-input" || first.Body != "approve?" || first.Urgency != UrgencyNormal {
+		t.Errorf("first notification = %+v", first)
+	}
+	if second.ReplacesID != 1 || second.Urgency != UrgencyCritical {
+		t.Errorf("second notification = %+v, want it to replace 1 with critical urgency", second)
+	}
+	if other.ReplacesID != 0 {
+		t.Errorf("notification of another beacon replaces %d", other.ReplacesID)
+	}
+	if len(first.Actions) != 0 {
+		t.Errorf("actions = %v, want none without a context", first.Actions)
+	}
+}
+
+func TestDesktop_CloseOnSilenceAndRunning(t *testing.T) {
+	bus := newFakeBus()
+	d, _ := newTestDesktop(t, bus)
+
+	d.Notify(event(beacon.EventAdded, "api", beacon.StatusWaiting, "m"), nil)
+	if err := d.Notify(event(beacon.EventUpdated, "api", beacon.StatusRunning, "m"), nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	d.Notify(event(beacon.EventUpdated, "api", beacon.StatusWaiting, "m"), nil)
+	if err := d.Notify(event(beacon.EventRemoved, "api", beacon.StatusWaiting, "m"), nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	// Nothing is left to close.
+	d.Notify(event(beacon.EventRemoved, "api", beacon.StatusWaiting, "m"), nil)
+
+	if len(bus.closed) != 2 || bus.closed[0] != 1 || bus.closed[1] != 2 {
+		t.Errorf("closed = %v, want [1 2]", bus.closed)
+	}
+	if bus.notified[1].ReplacesID != 0 {
+		t.Errorf("notification after closing replaces %d", bus.notified[1].ReplacesID)
+	}
+}
+
+func TestDesktop_FocusAction(t *testing.T) {
+	bus := newFakeBus()
+	d, spawned := newTestDesktop(t, bus)
+	ctx := &context.TmuxContext{SessionName: "main", WindowIndex: 1, PaneID: "%3"}
+
+	d.Notify(event(beacon.EventAdded, "api", beacon.StatusWaiting, "m"), ctx)
+	if got := bus.notified[0].Actions; len(got) != 2 || got[0].Key != ActionDefault || got[1].Key != ActionFocus {
+		t.Errorf("actions = %v, want default and focus", got)
+	}
+	if len(*spawned) != 1 || (*spawned)[0] != 1 {
+		t.Fatalf("spawned = %v, want a listener for notification 1", *spawned)
+	}
+
+	// The listener keeps waiting while the notification is replaced.
+	d.processes = fakeProcesses{101: true}
+	d.Notify(event(beacon.EventUpdated, "api", beacon.StatusBlocked, "m"), ctx)
+	if len(*spawned) != 1 {
+		t.Errorf("spawned = %v, want no second listener while the first is alive", *spawned)
+	}
+
+	// Once it has exited, the next emit starts another one.
+	d.processes = fakeProcesses{}
+	d.Notify(event(beacon.EventUpdated, "api", beacon.StatusBlocked, "m"), ctx)
+	if len(*spawned) != 2 {
+		t.Errorf("spawned = %v, want a new listener after the first exited", *spawned)
+	}
+}
+
+func TestDesktop_FallbackToNotifySend(t *testing.T) {
+	d, _ := newTestDesktop(t, newFakeBus())
+	d.dial = func() (Bus, error) { return nil, errors.New("no session bus") }
+	executor := &fakeExecutor{out: "7\n"}
+	d.executor = executor
+
+	if err := d.Notify(event(beacon.EventAdded, "api", beacon.StatusFailed, "boom"), nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if err := d.Notify(event(beacon.EventUpdated, "api", beacon.StatusFailed, "boom again"), nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	want := []string{
+		"notify-send --app-name=beacon --urgency=critical --print-id -- api: failed boom",
+		"notify-send --app-name=beacon --urgency=critical --print-id --replace-id=7 -- api: failed boom again",
+	}
+	if got := strings.Join(executor.calls, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("executed\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestDesktop_NotifyError(t *testing.T) {
+	bus := newFakeBus()
+	bus.err = errors.New("org.freedesktop.DBus.Error.ServiceUnknown")
+	d, _ := newTestDesktop(t, bus)
+
+	if err := d.Notify(event(beacon.EventAdded, "api", beacon.StatusWaiting, "m"), nil); err == nil {
+		t.Error("Notify() error = nil, want the bus error")
+	}
+}
+
+func TestListen(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals []Signal
+		want    string
+	}{
+		{
+			name: "action",
+			signals: []Signal{
+				{Type: SignalActionInvoked, ID: 9, Action: ActionFocus},
+				{Type: SignalActionInvoked, ID: 3, Action: ActionFocus},
+			},
+			want: ActionFocus,
+		},
+		{
+			name: "closed",
+			signals: []Signal{
+				{Type: SignalNotificationClosed, ID: 9},
+				{Type: SignalNotificationClosed, ID: 3},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := newFakeBus()
+			for _, s := range tt.signals {
+				bus.signals <- s
+			}
+			got, err := Listen(stdcontext.Background(), bus, 3)
+			if err != nil {
+				t.Fatalf("Listen() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Listen() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListen_Timeout(t *testing.T) {
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := Listen(ctx, newFakeBus(), 3); !errors.Is(err, stdcontext.DeadlineExceeded) {
+		t.Errorf("Listen() error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestListen_BusClosed(t *testing.T) {
+	bus := newFakeBus()
+	close(bus.signals)
+	if _, err := Listen(stdcontext.Background(), bus, 3); err == nil {
+		t.Error("Listen() error = nil, want an error when the bus closes")
+	}
+}
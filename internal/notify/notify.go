@@ -0,0 +1,192 @@
+// Package notify tells people about beacons outside the terminal: desktop
+// notifications through the freedesktop.org notification service
+// (org.freedesktop.Notifications) and HTTP webhooks.
+package notify
+
+import (
+	stdcontext "context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	busName   = "org.freedesktop.Notifications"
+	busPath   = dbus.ObjectPath("/org/freedesktop/Notifications")
+	busIface  = "org.freedesktop.Notifications"
+	appName   = "beacon"
+	callLimit = 2 * time.Second
+)
+
+// Action keys offered on notifications of beacons that can be jumped to.
+// ActionDefault is invoked by clicking the notification itself.
+const (
+	ActionDefault = "default"
+	ActionFocus   = "focus"
+)
+
+// errBusClosed is returned by Listen when the bus connection is lost.
+var errBusClosed = errors.New("notification bus closed")
+
+// Urgency is the urgency level hint of a notification.
+type Urgency byte
+
+const (
+	UrgencyLow      Urgency = 0
+	UrgencyNormal   Urgency = 1
+	UrgencyCritical Urgency = 2
+)
+
+// Action is a button shown on a notification.
+type Action struct {
+	Key   string
+	Label string
+}
+
+// Notification is a desktop notification to show.
+type Notification struct {
+	Summary string
+	Body    string
+	Urgency Urgency
+	Actions []Action
+	// ReplacesID is the ID of a shown notification to replace. Zero shows a new one.
+	ReplacesID uint32
+}
+
+// SignalType identifies a signal of the notification service.
+type SignalType string
+
+const (
+	SignalActionInvoked      SignalType = "ActionInvoked"
+	SignalNotificationClosed SignalType = "NotificationClosed"
+)
+
+// Signal is emitted by the notification service when a notification is
+// activated or closed. Action is set for SignalActionInvoked only.
+type Signal struct {
+	Type   SignalType
+	ID     uint32
+	Action string
+}
+
+// Bus is a connection to the notification service. The session bus
+// implements it; tests substitute an in-process fake.
+type Bus interface {
+	// Notify shows n and returns its notification ID.
+	Notify(n Notification) (uint32, error)
+	CloseNotification(id uint32) error
+	// Signals subscribes to the signals of the notification service.
+	// The channel is closed when the bus is.
+	Signals() (<-chan Signal, error)
+	Close() error
+}
+
+// Dialer connects to the notification service.
+type Dialer func() (Bus, error)
+
+// sessionBus is a Bus on the D-Bus session bus.
+type sessionBus struct {
+	conn *dbus.Conn
+	obj  dbus.BusObject
+}
+
+// DialSession connects to the notification service on the session bus.
+func DialSession() (Bus, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, err
+	}
+	return &sessionBus{conn: conn, obj: conn.Object(busName, busPath)}, nil
+}
+
+func (b *sessionBus) call(member string, args ...any) *dbus.Call {
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), callLimit)
+	defer cancel()
+	return b.obj.CallWithContext(ctx, busIface+"."+member, 0, args...)
+}
+
+func (b *sessionBus) Notify(n Notification) (uint32, error) {
+	actions := []string{}
+	for _, a := range n.Actions {
+		actions = append(actions, a.Key, a.Label)
+	}
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(byte(n.Urgency)),
+	}
+	var id uint32
+	err := b.call("Notify", appName, n.ReplacesID, "", n.Summary, n.Body, actions, hints, int32(-1)).Store(&id)
+	return id, err
+}
+
+func (b *sessionBus) CloseNotification(id uint32) error {
+	return b.call("CloseNotification", id).Err
+}
+
+func (b *sessionBus) Signals() (<-chan Signal, error) {
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), callLimit)
+	defer cancel()
+	err := b.conn.AddMatchSignalContext(ctx, dbus.WithMatchInterface(busIface), dbus.WithMatchObjectPath(busPath))
+	if err != nil {
+		return nil, err
+	}
+	received := make(chan *dbus.Signal, 16)
+	b.conn.Signal(received)
+
+	signals := make(chan Signal)
+	go func() {
+		// received is closed along with the connection.
+		defer close(signals)
+		for m := range received {
+			member, ok := strings.CutPrefix(m.Name, busIface+".")
+			if !ok || m.Path != busPath || len(m.Body) == 0 {
+				continue
+			}
+			id, _ := m.Body[0].(uint32)
+			s := Signal{Type: SignalType(member), ID: id}
+			switch s.Type {
+			case SignalActionInvoked:
+				if len(m.Body) > 1 {
+					s.Action, _ = m.Body[1].(string)
+				}
+			case SignalNotificationClosed:
+			default:
+				continue
+			}
+			signals <- s
+		}
+	}()
+	return signals, nil
+}
+
+func (b *sessionBus) Close() error {
+	return b.conn.Close()
+}
+
+// Listen waits until notification id is activated or closed and returns
+// the invoked action key, or "" if it was closed. It returns ctx.Err()
+// when ctx is done first.
+func Listen(ctx stdcontext.Context, bus Bus, id uint32) (string, error) {
+	signals, err := bus.Signals()
+	if err != nil {
+		return "", err
+	}
+	for {
+		select {
+		case s, ok := <-signals:
+			if !ok {
+				return "", errBusClosed
+			}
+			if s.ID != id {
+				continue
+			}
+			if s.Type == SignalActionInvoked {
+				return s.Action, nil
+			}
+			return "", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package notify
+
+import (
+	"bytes"
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+const (
+	// baseBackoff is the delay before the first retry; it doubles after each.
+	baseBackoff = 500 * time.Millisecond
+	// maxBackoff caps the delay, including one requested with Retry-After.
+	maxBackoff = 30 * time.Second
+	// defaultTimeout applies to each request when none is configured.
+	defaultTimeout = 5 * time.Second
+	// maxDelivery bounds the delivery of one event, retries included, so
+	// that the emit or silence of an agent hook is not held up for long.
+	maxDelivery = 15 * time.Second
+)
+
+// webhookFormat is a built-in body template with the headers it needs.
+type webhookFormat struct {
+	contentType string
+	body        string
+	headers     map[string]string
+}
+
+// webhookFormats are the built-in body templates selectable by name.
+var webhookFormats = map[string]webhookFormat{
+	"json": {
+		contentType: "application/json",
+		body:        `{{json .}}`,
+	},
+	"slack": {
+		contentType: "application/json",
+		body:        `{"text":{{json (summary .)}}}`,
+	},
+	"discord": {
+		contentType: "application/json",
+		body:        `{"content":{{json (summary .)}}}`,
+	},
+	"ntfy": {
+		contentType: "text/plain; charset=utf-8",
+		body:        `{{summary .}}`,
+		headers: map[string]string{
+			"Title":    "beacon: {{.ID}}",
+			"Priority": "{{priority .}}",
+			"Tags":     "{{.Status}}",
+		},
+	},
+}
+
+// WebhookFormats lists the names of the built-in body templates.
+func WebhookFormats() []string {
+	return slices.Sorted(maps.Keys(webhookFormats))
+}
+
+// WebhookOptions configures a Webhook.
+type WebhookOptions struct {
+	// Name identifies the webhook in errors.
+	Name string
+	URL  string
+	// Format names a built-in body template; empty means "json".
+	Format string
+	// Template is a Go text/template for the body, executed with a
+	// beacon.Payload. It overrides the body of Format.
+	Template string
+	// Headers are added to each request. Values are templates like Template.
+	Headers map[string]string
+	// Timeout bounds each attempt; zero means 5s.
+	Timeout time.Duration
+	// Retries is how often a failed request is repeated, with exponential
+	// backoff, as long as the delivery of the event takes less than 15s.
+	Retries int
+}
+
+// Webhook is a beacon.Notifier posting every event to a URL.
+// Network errors and 429 and 5xx responses are retried; other failures are not.
+// Retrying stops once the delivery of an event has taken maxDelivery.
+type Webhook struct {
+	name        string
+	url         string
+	body        *template.Template
+	headers     map[string]*template.Template
+	contentType string
+	timeout     time.Duration
+	retries     int
+	budget      time.Duration
+	client      *http.Client
+	now         func() time.Time
+	sleep       func(time.Duration)
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"summary":  summary,
+	"priority": priority,
+}
+
+// NewWebhook creates a Webhook, parsing its templates.
+func NewWebhook(opts WebhookOptions) (*Webhook, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("webhook %s: url is required", opts.Name)
+	}
+	if opts.Format == "" {
+		opts.Format = "json"
+	}
+	format, ok := webhookFormats[opts.Format]
+	if !ok {
+		return nil, fmt.Errorf("webhook %s: unknown format %q (supported: %s)", opts.Name, opts.Format, strings.Join(WebhookFormats(), ", "))
+	}
+	bodyText := format.body
+	if opts.Template != "" {
+		bodyText = opts.Template
+	}
+	body, err := template.New("body").Funcs(templateFuncs).Parse(bodyText)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %s: %w", opts.Name, err)
+	}
+
+	w := &Webhook{
+		name:        opts.Name,
+		url:         opts.URL,
+		body:        body,
+		headers:     map[string]*template.Template{},
+		contentType: format.contentType,
+		timeout:     opts.Timeout,
+		retries:     max(opts.Retries, 0),
+		budget:      maxDelivery,
+		client:      http.DefaultClient,
+		now:         time.Now,
+		sleep:       time.Sleep,
+	}
+	if w.timeout <= 0 {
+		w.timeout = defaultTimeout
+	}
+	headers := maps.Clone(format.headers)
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	maps.Copy(headers, opts.Headers)
+	for name, value := range headers {
+		tmpl, err := template.New(name).Funcs(templateFuncs).Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %s: header %s: %w", opts.Name, name, err)
+		}
+		w.headers[name] = tmpl
+	}
+	return w, nil
+}
+
+// Notify posts the event, retrying failed attempts until the retries or the
+// time allowed for the delivery run out.
+func (w *Webhook) Notify(event beacon.Event, ctx context.Context) error {
+	payload := beacon.NewPayload(event, ctx, w.now())
+	body, err := execute(w.body, payload)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", w.name, err)
+	}
+	header := http.Header{}
+	header.Set("Content-Type", w.contentType)
+	header.Set("User-Agent", appName)
+	for name, tmpl := range w.headers {
+		value, err := execute(tmpl, payload)
+		if err != nil {
+			return fmt.Errorf("webhook %s: header %s: %w", w.name, name, err)
+		}
+		header.Set(name, value)
+	}
+
+	deadline := w.now().Add(w.budget)
+	backoff := baseBackoff
+	for attempt := 0; ; attempt++ {
+		wait, err := w.post(body, header, min(w.timeout, deadline.Sub(w.now())))
+		if err == nil {
+			return nil
+		}
+		var permanent *permanentError
+		if attempt >= w.retries || errors.As(err, &permanent) {
+			return fmt.Errorf("webhook %s: %w", w.name, err)
+		}
+		delay := min(max(wait, backoff), maxBackoff)
+		if w.now().Add(delay).After(deadline) {
+			return fmt.Errorf("webhook %s: no time left to retry within %s: %w", w.name, w.budget, err)
+		}
+		w.sleep(delay)
+		backoff *= 2
+	}
+}
+
+// Webhooks is a beacon.Notifier posting every event to several webhooks at
+// once, so that together they take no longer than the slowest of them.
+type Webhooks []*Webhook
+
+// Notify posts the event to all webhooks and joins their errors.
+func (ws Webhooks) Notify(event beacon.Event, ctx context.Context) error {
+	errs := make([]error, len(ws))
+	var wg sync.WaitGroup
+	for i, w := range ws {
+		wg.Go(func() { errs[i] = w.Notify(event, ctx) })
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// permanentError is a failure that retrying will not fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// post sends a single request that may take up to timeout. On a retryable
+// failure it returns how long the server asked to wait, if it did.
+func (w *Webhook) post(body string, header http.Header, timeout time.Duration) (time.Duration, error) {
+	ctx, cancel := stdcontext.WithTimeout(stdcontext.Background(), timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, strings.NewReader(body))
+	if err != nil {
+		return 0, &permanentError{err}
+	}
+	req.Header = header.Clone()
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return 0, nil
+	}
+
+	err = fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(snippet))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return time.Duration(seconds) * time.Second, err
+	}
+	return 0, &permanentError{err}
+}
+
+func execute(tmpl *template.Template, payload beacon.Payload) (string, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// summary describes the event in one line for chat messages.
+func summary(p beacon.Payload) string {
+	if p.Event == beacon.EventRemoved {
+		return p.ID + " was silenced"
+	}
+	s := fmt.Sprintf("%s is %s", p.ID, p.Status)
+	if p.Message != "" {
+		s += ": " + p.Message
+	}
+	if p.Location != "" {
+		s += " (" + p.Location + ")"
+	}
+	return s
+}
+
+// priority maps the event to an ntfy message priority.
+func priority(p beacon.Payload) string {
+	switch {
+	case p.Event == beacon.EventRemoved:
+		return "low"
+	case p.Status == beacon.StatusBlocked || p.Status == beacon.StatusFailed:
+		return "high"
+	}
+	return "default"
+}
@@ -0,0 +1,328 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+	"github.com/monochromegane/beacon/internal/context"
+)
+
+// recordedRequest is a request received by a test server.
+type recordedRequest struct {
+	header http.Header
+	body   string
+}
+
+// newRecordingServer starts a server answering with the given status codes
+// in turn, repeating the last one, and records the requests it receives.
+func newRecordingServer(t *testing.T, statuses ...int) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, recordedRequest{header: r.Header.Clone(), body: string(body)})
+		status := statuses[min(len(requests), len(statuses))-1]
+		mu.Unlock()
+		if r.Method != http.MethodPost {
+			status = http.StatusMethodNotAllowed
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, http.StatusText(status))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newTestWebhook(t *testing.T, opts WebhookOptions) (*Webhook, *[]time.Duration) {
+	t.Helper()
+	if opts.Name == "" {
+		opts.Name = "test"
+	}
+	w, err := NewWebhook(opts)
+	if err != nil {
+		t.Fatalf("NewWebhook() error = %v", err)
+	}
+	var sleeps []time.Duration
+	w.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	w.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return w, &sleeps
+}
+
+func TestWebhook_JSON(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusNoContent)
+	w, _ := newTestWebhook(t, WebhookOptions{URL: server.URL})
+	ctx := &context.TmuxContext{SessionName: "main", WindowIndex: 1, PaneIndex: 0, PaneID: "%3"}
+	event := beacon.Event{
+		Type:  beacon.EventAdded,
+		ID:    "api",
+		State: beacon.State{ID: "api", Status: beacon.StatusWaiting, Message: "approve?", Labels: beacon.Labels{"project": "web"}},
+	}
+
+	if err := w.Notify(event, ctx); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(*requests))
+	}
+	req := (*requests)[0]
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte(req.body), &got); err != nil {
+		t.Fatalf("body %q is not JSON: %v", req.body, err)
+	}
+	want := map[string]any{
+		"event":     "added",
+		"id":        "api",
+		"status":    "waiting-for-input",
+		"message":   "approve?",
+		"labels":    map[string]any{"project": "web"},
+		"timestamp": "2026-01-02T03:04:05Z",
+		"location":  "main:1.0",
+	}
+	for key, value := range want {
+		if gotJSON, wantJSON := mustJSON(t, got[key]), mustJSON(t, value); gotJSON != wantJSON {
+			t.Errorf("body[%q] = %s, want %s", key, gotJSON, wantJSON)
+		}
+	}
+	tmux, _ := got["context"].(map[string]any)["tmux"].(map[string]any)
+	if tmux["pane_id"] != "%3" {
+		t.Errorf("body context = %v, want the tmux context", got["context"])
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestWebhook_Formats(t *testing.T) {
+	event := beacon.Event{
+		Type:  beacon.EventUpdated,
+		ID:    "api",
+		State: beacon.State{ID: "api", Status: beacon.StatusBlocked, Message: `needs "sudo"`},
+	}
+	tests := []struct {
+		format  string
+		body    string
+		headers map[string]string
+	}{
+		{format: "slack", body: `{"text":"api is blocked: needs \"sudo\""}`},
+		{format: "discord", body: `{"content":"api is blocked: needs \"sudo\""}`},
+		{
+			format:  "ntfy",
+			body:    `api is blocked: needs "sudo"`,
+			headers: map[string]string{"Title": "beacon: api", "Priority": "high", "Tags": "blocked"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			server, requests := newRecordingServer(t, http.StatusOK)
+			w, _ := newTestWebhook(t, WebhookOptions{URL: server.URL, Format: tt.format})
+			if err := w.Notify(event, nil); err != nil {
+				t.Fatalf("Notify() error = %v", err)
+			}
+			req := (*requests)[0]
+			if req.body != tt.body {
+				t.Errorf("body = %s, want %s", req.body, tt.body)
+			}
+			for name, value := range tt.headers {
+				if got := req.header.Get(name); got != value {
+					t.Errorf("header %s = %q, want %q", name, got, value)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhook_TemplateAndHeaders(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusOK)
+	w, _ := newTestWebhook(t, WebhookOptions{
+		URL:      server.URL,
+		Template: `{{.Event}} {{.ID}} {{.Timestamp.Unix}}`,
+		Headers:  map[string]string{"Authorization": "Bearer secret", "X-Beacon": "{{.ID}}", "Content-Type": "text/plain"},
+	})
+	event := beacon.Event{Type: beacon.EventRemoved, ID: "api", State: beacon.State{ID: "api"}}
+
+	if err := w.Notify(event, nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	req := (*requests)[0]
+	if req.body != "removed api 1767323045" {
+		t.Errorf("body = %q", req.body)
+	}
+	for name, value := range map[string]string{"Authorization": "Bearer secret", "X-Beacon": "api", "Content-Type": "text/plain"} {
+		if got := req.header.Get(name); got != value {
+			t.Errorf("header %s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestWebhook_Retries(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+	w, sleeps := newTestWebhook(t, WebhookOptions{URL: server.URL, Retries: 3})
+
+	if err := w.Notify(beacon.Event{Type: beacon.EventAdded, ID: "api"}, nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(*requests) != 3 {
+		t.Errorf("got %d requests, want 3", len(*requests))
+	}
+	if want := []time.Duration{500 * time.Millisecond, time.Second}; !slices.Equal(*sleeps, want) {
+		t.Errorf("backoff = %v, want %v", *sleeps, want)
+	}
+}
+
+func TestWebhook_RetriesExhausted(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusBadGateway)
+	w, sleeps := newTestWebhook(t, WebhookOptions{URL: server.URL, Retries: 2})
+
+	err := w.Notify(beacon.Event{Type: beacon.EventAdded, ID: "api"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "webhook test: 502 Bad Gateway") {
+		t.Errorf("Notify() error = %v, want the last status", err)
+	}
+	if len(*requests) != 3 || len(*sleeps) != 2 {
+		t.Errorf("got %d requests and %d sleeps, want 3 and 2", len(*requests), len(*sleeps))
+	}
+}
+
+func TestWebhook_NoRetryOnClientError(t *testing.T) {
+	server, requests := newRecordingServer(t, http.StatusNotFound)
+	w, _ := newTestWebhook(t, WebhookOptions{URL: server.URL, Retries: 2})
+
+	if err := w.Notify(beacon.Event{Type: beacon.EventAdded, ID: "api"}, nil); err == nil {
+		t.Error("Notify() error = nil, want 404")
+	}
+	if len(*requests) != 1 {
+		t.Errorf("got %d requests, want 1", len(*requests))
+	}
+}
+
+func TestWebhook_RetryAfter(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+	w, sleeps := newTestWebhook(t, WebhookOptions{URL: server.URL, Retries: 1})
+
+	if err := w.Notify(beacon.Event{Type: beacon.EventAdded, ID: "api"}, nil); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if want := []time.Duration{3 * time.Second}; !slices.Equal(*sleeps, want) {
+		t.Errorf("backoff = %v, want %v", *sleeps, want)
+	}
+}
+
+func TestWebhook_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	w, _ := newTestWebhook(t, WebhookOptions{URL: server.URL, Timeout: 20 * time.Millisecond})
+
+	start := time.Now()
+	if err := w.Notify(beacon.Event{Type: beacon.EventAdded, ID: "api"}, nil); err == nil {
+		t.Error("Notify() error = nil, want a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Notify() took %v despite the timeout", elapsed)
+	}
+}
+
+func TestWebhook_DeliveryLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	w, sleeps := newTestWebhook(t, WebhookOptions{URL: server.URL, Retries: 5})
+	clock := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	w.now = func() time.Time { return clock }
+	w.sleep = func(d time.Duration) {
+		*sleeps = append(*sleeps, d)
+		clock = clock.Add(d)
+	}
+
+	err := w.Notify(beacon.Event{Type: beacon.EventAdded, ID: "api"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "webhook test: no time left to retry within 15s: 503 Service Unavailable") {
+		t.Errorf("Notify() error = %v, want to give up", err)
+	}
+	if len(*sleeps) != 0 {
+		t.Errorf("slept %v past the delivery limit", *sleeps)
+	}
+}
+
+func TestWebhooks_DeliveryLimit(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	var webhooks Webhooks
+	for _, name := range []string{"a", "b", "c"} {
+		w, _ := newTestWebhook(t, WebhookOptions{Name: name, URL: server.URL, Retries: 2})
+		w.now = time.Now
+		w.sleep = time.Sleep
+		w.budget = 200 * time.Millisecond
+		webhooks = append(webhooks, w)
+	}
+
+	start := time.Now()
+	err := webhooks.Notify(beacon.Event{Type: beacon.EventAdded, ID: "api"}, nil)
+	elapsed := time.Since(start)
+	if err == nil || strings.Count(err.Error(), "webhook ") != 3 {
+		t.Errorf("Notify() error = %v, want one error per webhook", err)
+	}
+	// Each webhook stops at its limit and all of them run at once.
+	if elapsed > time.Second {
+		t.Errorf("Notify() took %v, want about 200ms for all webhooks", elapsed)
+	}
+}
+
+func TestNewWebhook_Errors(t *testing.T) {
+	tests := []struct {
+		opts WebhookOptions
+		want string
+	}{
+		{WebhookOptions{Name: "a"}, "webhook a: url is required"},
+		{WebhookOptions{Name: "a", URL: "http://x", Format: "teams"}, `webhook a: unknown format "teams" (supported: discord, json, ntfy, slack)`},
+		{WebhookOptions{Name: "a", URL: "http://x", Template: "{{.ID"}, "webhook a: template: body:1: unclosed action"},
+		{WebhookOptions{Name: "a", URL: "http://x", Headers: map[string]string{"X": "{{"}}, "webhook a: header X:"},
+	}
+	for _, tt := range tests {
+		_, err := NewWebhook(tt.opts)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("NewWebhook(%+v) error = %v, want prefix %q", tt.opts, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package picker
+
+import (
+	"strings"
+	"unicode"
+)
+
+// match reports whether every rune of pattern appears in text in order,
+// ignoring case, and scores the match. Higher scores are better matches:
+// consecutive runes and matches at word starts are rewarded, gaps are penalized.
+func match(pattern, text string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+
+	score, pi, last := 0, 0, -1
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if t[ti] != p[pi] {
+			continue
+		}
+		switch {
+		case last >= 0 && ti == last+1:
+			score += 8
+		case ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]):
+			score += 6
+		default:
+			score += 1
+		}
+		if last >= 0 {
+			score -= min(ti-last-1, 4)
+		}
+		last = ti
+		pi++
+	}
+	if pi < len(p) {
+		return 0, false
+	}
+	return score, true
+}
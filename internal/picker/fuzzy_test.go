@@ -0,0 +1,37 @@
+package picker
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		want          bool
+	}{
+		{"", "anything", true},
+		{"api", "session-api waiting", true},
+		{"API", "session-api waiting", true},
+		{"swt", "session-api waiting", true},
+		{"tws", "session-api waiting", false},
+		{"xyz", "session-api waiting", false},
+		{"日本", "日本語の説明", true},
+	}
+	for _, tt := range tests {
+		if _, got := match(tt.pattern, tt.text); got != tt.want {
+			t.Errorf("match(%q, %q) = %v, want %v", tt.pattern, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMatch_Score(t *testing.T) {
+	consecutive, _ := match("api", "my-api-server")
+	scattered, _ := match("api", "a big pile")
+	if consecutive <= scattered {
+		t.Errorf("consecutive score %d <= scattered score %d", consecutive, scattered)
+	}
+
+	wordStart, _ := match("web", "agent web")
+	midWord, _ := match("web", "cobweb")
+	if wordStart <= midWord {
+		t.Errorf("word start score %d <= mid-word score %d", wordStart, midWord)
+	}
+}
@@ -0,0 +1,80 @@
+package picker
+
+import "unicode/utf8"
+
+// keyKind identifies a key press understood by the picker.
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyEnter
+	keyEscape
+	keyInterrupt
+	keyBackspace
+	keyUp
+	keyDown
+	keyClear
+	keySilence
+	keyUnknown
+)
+
+// key is a decoded key press. r is set for keyRune.
+type key struct {
+	kind keyKind
+	r    rune
+}
+
+// parseKeys decodes a chunk of raw terminal input into key presses.
+// A lone ESC byte is treated as the Escape key; ESC followed by '[' or 'O'
+// starts a cursor key sequence.
+func parseKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) >= 3 && (b[1] == '[' || b[1] == 'O') {
+				switch b[2] {
+				case 'A':
+					keys = append(keys, key{kind: keyUp})
+				case 'B':
+					keys = append(keys, key{kind: keyDown})
+				default:
+					keys = append(keys, key{kind: keyUnknown})
+				}
+				b = b[3:]
+				continue
+			}
+			keys = append(keys, key{kind: keyEscape})
+			b = b[1:]
+		case c == '\r' || c == '\n':
+			keys = append(keys, key{kind: keyEnter})
+			b = b[1:]
+		case c == 0x03:
+			keys = append(keys, key{kind: keyInterrupt})
+			b = b[1:]
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, key{kind: keyBackspace})
+			b = b[1:]
+		case c == 0x10: // Ctrl-P
+			keys = append(keys, key{kind: keyUp})
+			b = b[1:]
+		case c == 0x0e: // Ctrl-N
+			keys = append(keys, key{kind: keyDown})
+			b = b[1:]
+		case c == 0x15: // Ctrl-U
+			keys = append(keys, key{kind: keyClear})
+			b = b[1:]
+		case c == 0x18: // Ctrl-X
+			keys = append(keys, key{kind: keySilence})
+			b = b[1:]
+		case c < 0x20:
+			keys = append(keys, key{kind: keyUnknown})
+			b = b[1:]
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, key{kind: keyRune, r: r})
+			b = b[size:]
+		}
+	}
+	return keys
+}
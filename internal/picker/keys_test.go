@@ -0,0 +1,29 @@
+package picker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []key
+	}{
+		{"runes", "ab", []key{{kind: keyRune, r: 'a'}, {kind: keyRune, r: 'b'}}},
+		{"multibyte", "日", []key{{kind: keyRune, r: '日'}}},
+		{"enter", "\r", []key{{kind: keyEnter}}},
+		{"escape", "\x1b", []key{{kind: keyEscape}}},
+		{"arrows", "\x1b[A\x1b[B", []key{{kind: keyUp}, {kind: keyDown}}},
+		{"application arrows", "\x1bOA", []key{{kind: keyUp}}},
+		{"ctrl keys", "\x10\x0e\x15\x18\x03", []key{{kind: keyUp}, {kind: keyDown}, {kind: keyClear}, {kind: keySilence}, {kind: keyInterrupt}}},
+		{"backspace", "\x7f\x08", []key{{kind: keyBackspace}, {kind: keyBackspace}}},
+		{"unknown", "\x01\x1b[C", []key{{kind: keyUnknown}, {kind: keyUnknown}}},
+	}
+	for _, tt := range tests {
+		if got := parseKeys([]byte(tt.input)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseKeys(%q) = %v, want %v", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
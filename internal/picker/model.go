@@ -0,0 +1,176 @@
+package picker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/monochromegane/beacon/internal/render"
+)
+
+const helpLine = "enter: jump  ctrl-x: silence  ctrl-u: clear  esc: quit"
+
+// Item is a beacon shown in the picker.
+type Item struct {
+	ID       string
+	Status   string
+	Message  string
+	Location string
+	// Preview is shown below the list while the item is selected.
+	Preview string
+}
+
+// text returns the string the query is matched against.
+func (i Item) text() string {
+	return strings.Join([]string{i.ID, i.Status, i.Location, i.Message}, " ")
+}
+
+// model holds the picker state independent of the terminal.
+type model struct {
+	items   []Item
+	query   []rune
+	matches []int
+	cursor  int
+	offset  int
+}
+
+func newModel(items []Item) *model {
+	m := &model{items: items}
+	m.filter()
+	return m
+}
+
+// filter recomputes the matching items for the current query, best first.
+func (m *model) filter() {
+	type scored struct {
+		index int
+		score int
+	}
+	var hits []scored
+	for i, item := range m.items {
+		if score, ok := match(string(m.query), item.text()); ok {
+			hits = append(hits, scored{i, score})
+		}
+	}
+	sort.SliceStable(hits, func(a, b int) bool { return hits[a].score > hits[b].score })
+
+	m.matches = m.matches[:0]
+	for _, hit := range hits {
+		m.matches = append(m.matches, hit.index)
+	}
+	m.cursor = min(m.cursor, max(len(m.matches)-1, 0))
+}
+
+// selected returns the item under the cursor.
+func (m *model) selected() (Item, bool) {
+	if len(m.matches) == 0 {
+		return Item{}, false
+	}
+	return m.items[m.matches[m.cursor]], true
+}
+
+// remove drops the item with the given ID.
+func (m *model) remove(id string) {
+	for i, item := range m.items {
+		if item.ID == id {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			break
+		}
+	}
+	m.filter()
+}
+
+// typeRune appends a rune to the query.
+func (m *model) typeRune(r rune) {
+	m.query = append(m.query, r)
+	m.cursor = 0
+	m.filter()
+}
+
+// backspace removes the last rune of the query.
+func (m *model) backspace() {
+	if len(m.query) == 0 {
+		return
+	}
+	m.query = m.query[:len(m.query)-1]
+	m.filter()
+}
+
+// clear empties the query.
+func (m *model) clear() {
+	m.query = m.query[:0]
+	m.cursor = 0
+	m.filter()
+}
+
+// move shifts the cursor by delta, clamped to the matches.
+func (m *model) move(delta int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.cursor = min(max(m.cursor+delta, 0), len(m.matches)-1)
+}
+
+// render draws a full frame for a terminal of the given size.
+// Lines are separated by CRLF because output post-processing is off in raw mode.
+func (m *model) render(width, height int) string {
+	width, height = max(width, 10), max(height, 4)
+
+	listHeight := height - 3
+	item, hasSelection := m.selected()
+	if hasSelection && item.Preview != "" {
+		listHeight = max((height-3)/2, 1)
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+listHeight {
+		m.offset = m.cursor - listHeight + 1
+	}
+
+	var lines []string
+	lines = append(lines, render.Truncate("> "+string(m.query), width))
+	for row := 0; row < listHeight; row++ {
+		i := m.offset + row
+		if i >= len(m.matches) {
+			lines = append(lines, "")
+			continue
+		}
+		line := render.Truncate(formatItem(m.items[m.matches[i]]), width-2)
+		if i == m.cursor {
+			lines = append(lines, "\x1b[7m> "+line+"\x1b[0m")
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	lines = append(lines, render.Truncate(fmt.Sprintf("── %d/%d %s", len(m.matches), len(m.items), strings.Repeat("─", width)), width))
+
+	if hasSelection && item.Preview != "" {
+		previewHeight := height - len(lines) - 1
+		for i, line := range strings.Split(item.Preview, "\n") {
+			if i >= previewHeight {
+				break
+			}
+			lines = append(lines, render.Truncate(render.StripControl(line), width))
+		}
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, render.Truncate(helpLine, width))
+
+	return "\x1b[H\x1b[2J" + strings.Join(lines, "\r\n")
+}
+
+// formatItem renders an item as a single list line.
+func formatItem(item Item) string {
+	fields := []string{item.ID, item.Status}
+	if item.Location != "" {
+		fields = append(fields, item.Location)
+	}
+	fields = append(fields, item.Message)
+	for i, field := range fields {
+		fields[i] = render.SingleLine(field)
+	}
+	return strings.Join(fields, "  ")
+}
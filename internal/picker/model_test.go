@@ -0,0 +1,180 @@
+package picker
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testItems() []Item {
+	return []Item{
+		{ID: "agent-web", Status: "waiting-for-input", Message: "Approve edit?", Location: "web:1.0", Preview: `{"session_name":"web"}`},
+		{ID: "agent-api", Status: "running", Message: "Running tests", Location: "api:0.1"},
+		{ID: "agent-docs", Status: "done", Message: "Finished"},
+	}
+}
+
+func TestModel_Filter(t *testing.T) {
+	m := newModel(testItems())
+	if len(m.matches) != 3 {
+		t.Fatalf("matches = %d, want 3", len(m.matches))
+	}
+
+	for _, r := range "api" {
+		m.typeRune(r)
+	}
+	item, ok := m.selected()
+	if !ok || item.ID != "agent-api" {
+		t.Errorf("selected() = %+v, want agent-api", item)
+	}
+	if len(m.matches) != 2 {
+		t.Errorf("matches = %d, want 2 (agent-api and a scattered match)", len(m.matches))
+	}
+
+	m.backspace()
+	m.backspace()
+	m.backspace()
+	if len(m.matches) != 3 {
+		t.Errorf("matches after backspace = %d, want 3", len(m.matches))
+	}
+
+	m.typeRune('z')
+	m.typeRune('z')
+	if _, ok := m.selected(); ok {
+		t.Error("selected() ok with no matches")
+	}
+	m.clear()
+	if len(m.matches) != 3 {
+		t.Errorf("matches after clear = %d, want 3", len(m.matches))
+	}
+}
+
+func TestModel_Move(t *testing.T) {
+	m := newModel(testItems())
+
+	m.move(1)
+	m.move(1)
+	m.move(1)
+	if item, _ := m.selected(); item.ID != "agent-docs" {
+		t.Errorf("selected() after moving down = %s, want agent-docs", item.ID)
+	}
+	m.move(-5)
+	if item, _ := m.selected(); item.ID != "agent-web" {
+		t.Errorf("selected() after moving up = %s, want agent-web", item.ID)
+	}
+}
+
+func TestModel_Remove(t *testing.T) {
+	m := newModel(testItems())
+	m.move(2)
+	m.remove("agent-docs")
+
+	if len(m.items) != 2 {
+		t.Errorf("items = %d, want 2", len(m.items))
+	}
+	if item, _ := m.selected(); item.ID != "agent-api" {
+		t.Errorf("selected() after remove = %s, want agent-api", item.ID)
+	}
+}
+
+func TestModel_Render(t *testing.T) {
+	m := newModel(testItems())
+	frame := m.render(60, 12)
+	lines := strings.Split(strings.TrimPrefix(frame, "\x1b[H\x1b[2J"), "\r\n")
+
+	if len(lines) != 12 {
+		t.Fatalf("render() lines = %d, want 12", len(lines))
+	}
+	if lines[0] != "> " {
+		t.Errorf("render() prompt = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "\x1b[7m> agent-web  waiting-for-input  web:1.0  Approve edit?") {
+		t.Errorf("render() selected line = %q", lines[1])
+	}
+	if !strings.Contains(frame, `{"session_name":"web"}`) {
+		t.Error("render() does not show the preview of the selected item")
+	}
+	if lines[11] != helpLine {
+		t.Errorf("render() help = %q", lines[11])
+	}
+	for _, line := range lines {
+		if len([]rune(strings.NewReplacer("\x1b[7m", "", "\x1b[0m", "").Replace(line))) > 60 {
+			t.Errorf("render() line exceeds width: %q", line)
+		}
+	}
+}
+
+func TestModel_Render_ControlCharacters(t *testing.T) {
+	m := newModel([]Item{{ID: "a", Status: "running", Message: "\x1b]0;pwned\x07fix\ntests\x1b[31m", Preview: "{\x1b[2J}"}})
+	frame := strings.TrimPrefix(m.render(60, 8), "\x1b[H\x1b[2J")
+	plain := strings.NewReplacer("\x1b[7m", "", "\x1b[0m", "").Replace(frame)
+	if strings.ContainsAny(plain, "\x1b\x07") {
+		t.Errorf("render() passes control characters through: %q", frame)
+	}
+	if !strings.Contains(plain, "a  running  ]0;pwnedfix tests[31m") || !strings.Contains(plain, "{[2J}") {
+		t.Errorf("render() = %q, want the printable text kept", plain)
+	}
+}
+
+func TestRun_Jump(t *testing.T) {
+	var jumped string
+	opts := Options{OnJump: func(id string) error { jumped = id; return nil }}
+	size := func() (int, int) { return 80, 24 }
+
+	err := run(newModel(testItems()), strings.NewReader("docs\r"), &bytes.Buffer{}, size, opts)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if jumped != "agent-docs" {
+		t.Errorf("run() jumped to %q, want agent-docs", jumped)
+	}
+}
+
+func TestRun_Silence(t *testing.T) {
+	var silenced []string
+	var jumped string
+	opts := Options{
+		OnJump:    func(id string) error { jumped = id; return nil },
+		OnSilence: func(id string) error { silenced = append(silenced, id); return nil },
+	}
+	size := func() (int, int) { return 80, 24 }
+
+	err := run(newModel(testItems()), strings.NewReader("\x18\x18\r"), &bytes.Buffer{}, size, opts)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if strings.Join(silenced, ",") != "agent-web,agent-api" {
+		t.Errorf("run() silenced %v, want agent-web,agent-api", silenced)
+	}
+	if jumped != "agent-docs" {
+		t.Errorf("run() jumped to %q, want agent-docs", jumped)
+	}
+}
+
+func TestRun_Quit(t *testing.T) {
+	called := false
+	opts := Options{
+		OnJump:    func(id string) error { called = true; return nil },
+		OnSilence: func(id string) error { called = true; return nil },
+	}
+	size := func() (int, int) { return 80, 24 }
+
+	for _, input := range []string{"\x1b", "\x03", ""} {
+		if err := run(newModel(testItems()), strings.NewReader(input), &bytes.Buffer{}, size, opts); err != nil {
+			t.Errorf("run(%q) error = %v", input, err)
+		}
+	}
+	if called {
+		t.Error("run() called an action on quit")
+	}
+}
+
+func TestRun_ActionError(t *testing.T) {
+	opts := Options{OnJump: func(id string) error { return errors.New("target gone") }}
+	size := func() (int, int) { return 80, 24 }
+
+	if err := run(newModel(testItems()), strings.NewReader("\r"), &bytes.Buffer{}, size, opts); err == nil {
+		t.Error("run() expected error from OnJump, got nil")
+	}
+}
@@ -0,0 +1,92 @@
+package picker
+
+import (
+	"errors"
+	"io"
+)
+
+// Options configures the actions bound to picker keys.
+type Options struct {
+	// OnJump is called with the selected beacon ID when Enter is pressed.
+	// The picker exits afterwards.
+	OnJump func(id string) error
+	// OnSilence is called with the selected beacon ID when Ctrl-X is pressed.
+	// The beacon is removed from the list and the picker keeps running.
+	OnSilence func(id string) error
+}
+
+// Run shows a full-screen picker for items on the controlling terminal
+// until the user jumps to a beacon or quits.
+func Run(items []Item, opts Options) error {
+	tty, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+
+	restore, err := makeRaw(tty)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	// Switch to the alternate screen and hide the cursor while picking.
+	io.WriteString(tty, "\x1b[?1049h\x1b[?25l")
+	defer io.WriteString(tty, "\x1b[?25h\x1b[?1049l")
+
+	size := func() (int, int) {
+		width, height, err := terminalSize(tty)
+		if err != nil || width == 0 || height == 0 {
+			return 80, 24
+		}
+		return width, height
+	}
+	return run(newModel(items), tty, tty, size, opts)
+}
+
+// run is the input loop of the picker, separated from the terminal for testing.
+func run(m *model, in io.Reader, out io.Writer, size func() (int, int), opts Options) error {
+	buf := make([]byte, 256)
+	for {
+		width, height := size()
+		if _, err := io.WriteString(out, m.render(width, height)); err != nil {
+			return err
+		}
+
+		n, err := in.Read(buf)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, k := range parseKeys(buf[:n]) {
+			switch k.kind {
+			case keyEscape, keyInterrupt:
+				return nil
+			case keyEnter:
+				if item, ok := m.selected(); ok && opts.OnJump != nil {
+					return opts.OnJump(item.ID)
+				}
+			case keySilence:
+				if item, ok := m.selected(); ok && opts.OnSilence != nil {
+					if err := opts.OnSilence(item.ID); err != nil {
+						return err
+					}
+					m.remove(item.ID)
+				}
+			case keyUp:
+				m.move(-1)
+			case keyDown:
+				m.move(1)
+			case keyBackspace:
+				m.backspace()
+			case keyClear:
+				m.clear()
+			case keyRune:
+				m.typeRune(k.r)
+			}
+		}
+	}
+}
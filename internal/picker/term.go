@@ -0,0 +1,6 @@
+package picker
+
+import "errors"
+
+// ErrUnsupported is returned when the terminal cannot be put into raw mode.
+var ErrUnsupported = errors.New("interactive picker is not supported on this system")
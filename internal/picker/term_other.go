@@ -0,0 +1,17 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package picker
+
+import "os"
+
+func openTerminal() (*os.File, error) {
+	return nil, ErrUnsupported
+}
+
+func makeRaw(f *os.File) (func() error, error) {
+	return nil, ErrUnsupported
+}
+
+func terminalSize(f *os.File) (int, int, error) {
+	return 0, 0, ErrUnsupported
+}
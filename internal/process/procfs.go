@@ -0,0 +1,93 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported is returned when the proc filesystem is not available.
+var ErrUnsupported = errors.New("process inspection is not supported on this system")
+
+// Process identifies a process. StartTime distinguishes it from a later
+// process that reuses the same PID; zero means the start time is unknown.
+type Process struct {
+	PID       int    `json:"pid"`
+	StartTime uint64 `json:"start_time,omitempty"`
+}
+
+// ProcFS inspects processes through a proc filesystem.
+type ProcFS struct {
+	root string
+}
+
+// NewProcFS creates a new ProcFS reading from /proc.
+func NewProcFS() *ProcFS {
+	return &ProcFS{root: "/proc"}
+}
+
+// NewProcFSWithRoot creates a new ProcFS with a custom root directory (for testing).
+func NewProcFSWithRoot(root string) *ProcFS {
+	return &ProcFS{root: root}
+}
+
+// Identify returns the Process for pid, including its start time when available.
+func (p *ProcFS) Identify(pid int) Process {
+	startTime, _ := p.StartTime(pid)
+	return Process{PID: pid, StartTime: startTime}
+}
+
+// StartTime returns the start time of pid in clock ticks after boot.
+func (p *ProcFS) StartTime(pid int) (uint64, error) {
+	if _, err := os.Stat(p.root); err != nil {
+		return 0, ErrUnsupported
+	}
+	data, err := os.ReadFile(filepath.Join(p.root, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, err
+	}
+	return parseStartTime(string(data))
+}
+
+// Alive reports whether proc is still running.
+// A process whose start time differs from the recorded one is a different
+// process that reused the PID and is reported as not alive.
+func (p *ProcFS) Alive(proc Process) (bool, error) {
+	if proc.PID <= 0 {
+		return false, fmt.Errorf("invalid pid: %d", proc.PID)
+	}
+	startTime, err := p.StartTime(proc.PID)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if proc.StartTime != 0 && startTime != proc.StartTime {
+		return false, nil
+	}
+	return true, nil
+}
+
+// parseStartTime extracts the starttime field (22) from /proc/<pid>/stat.
+// The command name (field 2) may contain spaces and parentheses, so fields
+// are counted from the last closing parenthesis.
+func parseStartTime(stat string) (uint64, error) {
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, errors.New("unexpected stat format")
+	}
+	fields := strings.Fields(stat[end+1:])
+	// fields[0] is field 3 (state), so field 22 is fields[19].
+	if len(fields) < 20 {
+		return 0, errors.New("unexpected stat format")
+	}
+	startTime, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid start time")
+	}
+	return startTime, nil
+}
@@ -0,0 +1,118 @@
+package process
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func writeStat(t *testing.T, root string, pid int, comm string, startTime string) {
+	t.Helper()
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	stat := strconv.Itoa(pid) + " (" + comm + ") S 1 1 1 0 -1 4194560 100 0 0 0 1 2 0 0 20 0 1 0 " + startTime + " 1000 100\n"
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcFS_StartTime(t *testing.T) {
+	root := t.TempDir()
+	writeStat(t, root, 1234, "claude", "98765")
+	writeStat(t, root, 4321, "weird ) name (x", "555")
+	procfs := NewProcFSWithRoot(root)
+
+	got, err := procfs.StartTime(1234)
+	if err != nil {
+		t.Fatalf("StartTime() error = %v", err)
+	}
+	if got != 98765 {
+		t.Errorf("StartTime() = %d, want 98765", got)
+	}
+
+	got, err = procfs.StartTime(4321)
+	if err != nil {
+		t.Fatalf("StartTime() error = %v", err)
+	}
+	if got != 555 {
+		t.Errorf("StartTime() with parentheses in comm = %d, want 555", got)
+	}
+}
+
+func TestProcFS_StartTime_Invalid(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "1"), 0755)
+	os.WriteFile(filepath.Join(root, "1", "stat"), []byte("1 (init) S 0"), 0644)
+	writeStat(t, root, 2, "bad", "notanumber")
+	procfs := NewProcFSWithRoot(root)
+
+	if _, err := procfs.StartTime(1); err == nil {
+		t.Error("StartTime() expected error for short stat, got nil")
+	}
+	if _, err := procfs.StartTime(2); err == nil {
+		t.Error("StartTime() expected error for invalid start time, got nil")
+	}
+}
+
+func TestProcFS_Identify(t *testing.T) {
+	root := t.TempDir()
+	writeStat(t, root, 1234, "claude", "98765")
+	procfs := NewProcFSWithRoot(root)
+
+	got := procfs.Identify(1234)
+	if got != (Process{PID: 1234, StartTime: 98765}) {
+		t.Errorf("Identify() = %+v", got)
+	}
+	got = procfs.Identify(9999)
+	if got != (Process{PID: 9999}) {
+		t.Errorf("Identify() for missing process = %+v", got)
+	}
+}
+
+func TestProcFS_Alive(t *testing.T) {
+	root := t.TempDir()
+	writeStat(t, root, 1234, "claude", "98765")
+	procfs := NewProcFSWithRoot(root)
+
+	tests := []struct {
+		name string
+		proc Process
+		want bool
+	}{
+		{"running", Process{PID: 1234, StartTime: 98765}, true},
+		{"unknown start time", Process{PID: 1234}, true},
+		{"pid reused", Process{PID: 1234, StartTime: 11111}, false},
+		{"exited", Process{PID: 9999, StartTime: 98765}, false},
+	}
+	for _, tt := range tests {
+		got, err := procfs.Alive(tt.proc)
+		if err != nil {
+			t.Errorf("%s: Alive() error = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Alive() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProcFS_Alive_Unsupported(t *testing.T) {
+	procfs := NewProcFSWithRoot(filepath.Join(t.TempDir(), "missing"))
+
+	_, err := procfs.Alive(Process{PID: 1234})
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Alive() error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestProcFS_Alive_InvalidPID(t *testing.T) {
+	procfs := NewProcFSWithRoot(t.TempDir())
+
+	if _, err := procfs.Alive(Process{}); err == nil {
+		t.Error("Alive() expected error for pid 0, got nil")
+	}
+}
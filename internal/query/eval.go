@@ -0,0 +1,205 @@
+package query
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fields holds the values that filters and sort keys refer to.
+// Nested maps are reached with dotted paths.
+type Fields map[string]any
+
+// Lookup returns the value at a dotted path such as "context.tmux.session_name".
+func (f Fields) Lookup(path string) (any, bool) {
+	var current any = map[string]any(f)
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+type node interface {
+	eval(fields Fields) (bool, error)
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(fields Fields) (bool, error) {
+	ok, err := n.left.eval(fields)
+	if err != nil || !ok {
+		return false, err
+	}
+	return n.right.eval(fields)
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(fields Fields) (bool, error) {
+	ok, err := n.left.eval(fields)
+	if err != nil || ok {
+		return ok, err
+	}
+	return n.right.eval(fields)
+}
+
+type notNode struct{ n node }
+
+func (n notNode) eval(fields Fields) (bool, error) {
+	ok, err := n.n.eval(fields)
+	return !ok, err
+}
+
+// comparison compares a field with a literal. The literal is interpreted
+// according to the type of the field value: a duration for age, an RFC 3339
+// time for timestamps, a number for numeric fields and a string otherwise.
+type comparison struct {
+	field string
+	op    string
+	value string
+}
+
+func (c comparison) eval(fields Fields) (bool, error) {
+	actual, ok := fields.Lookup(c.field)
+	if !ok || actual == nil {
+		// A missing field differs from every value.
+		return c.op == "!=" || c.op == "!~", nil
+	}
+	if c.op == "~" || c.op == "!~" {
+		return glob(c.value, fmt.Sprint(actual)) == (c.op == "~"), nil
+	}
+
+	order, err := compareLiteral(actual, c.value)
+	if err != nil {
+		return false, fmt.Errorf("%s %s %s: %w", c.field, c.op, c.value, err)
+	}
+	switch c.op {
+	case "=":
+		return order == 0, nil
+	case "!=":
+		return order != 0, nil
+	case "<":
+		return order < 0, nil
+	case "<=":
+		return order <= 0, nil
+	case ">":
+		return order > 0, nil
+	case ">=":
+		return order >= 0, nil
+	}
+	return false, fmt.Errorf("unknown operator %s", c.op)
+}
+
+// compareLiteral compares a field value with a literal parsed to the same type.
+func compareLiteral(actual any, literal string) (int, error) {
+	switch v := actual.(type) {
+	case string:
+		return strings.Compare(v, literal), nil
+	case float64:
+		n, err := strconv.ParseFloat(literal, 64)
+		if err != nil {
+			return 0, fmt.Errorf("not a number")
+		}
+		return cmp.Compare(v, n), nil
+	case bool:
+		b, err := strconv.ParseBool(literal)
+		if err != nil {
+			return 0, fmt.Errorf("not a boolean")
+		}
+		return compareBool(v, b), nil
+	case time.Duration:
+		d, err := time.ParseDuration(literal)
+		if err != nil {
+			return 0, fmt.Errorf("not a duration")
+		}
+		return cmp.Compare(v, d), nil
+	case time.Time:
+		t, err := time.Parse(time.RFC3339, literal)
+		if err != nil {
+			return 0, fmt.Errorf("not an RFC 3339 time")
+		}
+		return v.Compare(t), nil
+	}
+	return 0, fmt.Errorf("cannot compare %T", actual)
+}
+
+// compareValues orders two field values for sorting. Missing values sort
+// last and values of different types are compared as text.
+func compareValues(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmp.Compare(x, y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return compareBool(x, y)
+		}
+	case time.Duration:
+		if y, ok := b.(time.Duration); ok {
+			return cmp.Compare(x, y)
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+	return 1
+}
+
+// glob reports whether s matches pattern, where * matches any run of
+// characters, including slashes, and ? matches a single character.
+func glob(pattern, s string) bool {
+	p, t := []rune(pattern), []rune(s)
+	pi, ti := 0, 0
+	star, mark := -1, 0
+	for ti < len(t) {
+		switch {
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, ti
+			pi++
+		case pi < len(p) && (p[pi] == '?' || p[pi] == t[ti]):
+			pi++
+			ti++
+		case star >= 0:
+			mark++
+			pi, ti = star+1, mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
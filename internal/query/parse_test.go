@@ -0,0 +1,127 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFilter(t *testing.T) {
+	fields := Fields{
+		"id":         "agent-1",
+		"status":     "waiting-for-input",
+		"message":    "Approve the /tmp/x edit?",
+		"emit_count": float64(3),
+		"age":        10 * time.Minute,
+		"updated_at": time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC),
+		"context": map[string]any{
+			"tmux": map[string]any{"session_name": "api-server", "window_index": float64(2)},
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"status = waiting-for-input", true},
+		{"status == running", false},
+		{"status != running", true},
+		{"id ~ 'agent-*'", true},
+		{"id !~ agent-?", false},
+		{`message ~ "*/tmp/*"`, true},
+		{"age > 5m", true},
+		{"age <= 5m", false},
+		{"emit_count >= 3", true},
+		{"emit_count < 3", false},
+		{"updated_at < 2026-01-02T00:00:00Z", true},
+		{"context.tmux.session_name ~ api*", true},
+		{"context.tmux.window_index = 2", true},
+		{"context.tmux.session_name = api and age > 5m", false},
+		{"context.tmux.session_name = api or age > 5m", true},
+		{"context.tmux.session_name ~ api* && age > 5m", true},
+		{"status = done || status = failed", false},
+		{"not status = done", true},
+		{"!(status = done or status = failed)", true},
+		{"(status = done or status = waiting-for-input) and age > 5m", true},
+		{"status = done or status = waiting-for-input and age > 1h", false},
+		{"context.screen.name = x", false},
+		{"context.screen.name != x", true},
+		{"message ~ 'Approve the*'", true},
+	}
+	for _, tt := range tests {
+		filter, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Errorf("ParseFilter(%q) error = %v", tt.expr, err)
+			continue
+		}
+		got, err := filter.Match(fields)
+		if err != nil {
+			t.Errorf("Match(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "unexpected end of expression"},
+		{"status", "unexpected end of expression"},
+		{"status =", "unexpected end of expression"},
+		{"status = done and", "unexpected end of expression"},
+		{"(status = done", "unexpected end of expression"},
+		{"status = done)", `unexpected ")" at offset 13`},
+		{"= done", `unexpected "=" at offset 0`},
+		{"status = 'done", "unterminated string at offset 9"},
+		{"status = done & age > 1m", `unexpected '&' at offset 14`},
+	}
+	for _, tt := range tests {
+		_, err := ParseFilter(tt.expr)
+		if err == nil {
+			t.Errorf("ParseFilter(%q) expected error, got nil", tt.expr)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseFilter(%q) error = %v, want %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestFilter_MatchTypeError(t *testing.T) {
+	filter, err := ParseFilter("age > soon")
+	if err != nil {
+		t.Fatalf("ParseFilter() error = %v", err)
+	}
+	_, err = filter.Match(Fields{"age": time.Minute})
+	if err == nil || !strings.Contains(err.Error(), "age > soon: not a duration") {
+		t.Errorf("Match() error = %v, want duration error", err)
+	}
+}
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "", true},
+		{"a*", "abc", true},
+		{"*c", "abc", true},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"*b*", "abc", true},
+		{"*/src/*", "/home/u/src/app", true},
+		{"a*b*c", "aXbYbZc", true},
+		{"a*b", "aXbY", false},
+		{"日本*", "日本語", true},
+	}
+	for _, tt := range tests {
+		if got := glob(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("glob(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+)
+
+// RecordFields returns the fields of a record: its JSON fields, with
+// timestamps as time.Time, and age, the time since the last update.
+func RecordFields(record beacon.Record, now time.Time) (Fields, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	var fields Fields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["created_at"] = record.CreatedAt
+	fields["updated_at"] = record.UpdatedAt
+	if !record.ExpiresAt.IsZero() {
+		fields["expires_at"] = record.ExpiresAt
+	}
+	if !record.UpdatedAt.IsZero() {
+		fields["age"] = now.Sub(record.UpdatedAt)
+	}
+	return fields, nil
+}
+
+// SortKey orders records by a field.
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// ParseSort parses sort keys such as "age" or "-updated_at".
+// A leading - sorts in descending order and a leading + in ascending order.
+func ParseSort(specs []string) ([]SortKey, error) {
+	keys := make([]SortKey, 0, len(specs))
+	for _, spec := range specs {
+		key := SortKey{Field: strings.TrimSpace(spec)}
+		switch {
+		case strings.HasPrefix(key.Field, "-"):
+			key.Field, key.Descending = key.Field[1:], true
+		case strings.HasPrefix(key.Field, "+"):
+			key.Field = key.Field[1:]
+		}
+		if key.Field == "" {
+			return nil, fmt.Errorf("invalid sort key %q", spec)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Select returns the records matching filter, ordered by keys.
+// A nil filter matches every record and records equal under all keys keep
+// their original order.
+func Select(records []beacon.Record, filter *Filter, keys []SortKey, now time.Time) ([]beacon.Record, error) {
+	type entry struct {
+		record beacon.Record
+		fields Fields
+	}
+	var selected []entry
+	for _, record := range records {
+		fields, err := RecordFields(record, now)
+		if err != nil {
+			return nil, err
+		}
+		if filter != nil {
+			ok, err := filter.Match(fields)
+			if err != nil {
+				return nil, fmt.Errorf("filter %s: %w", record.ID, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		selected = append(selected, entry{record, fields})
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		for _, key := range keys {
+			a, _ := selected[i].fields.Lookup(key.Field)
+			b, _ := selected[j].fields.Lookup(key.Field)
+			order := compareValues(a, b)
+			if order == 0 {
+				continue
+			}
+			if key.Descending && a != nil && b != nil {
+				order = -order
+			}
+			return order < 0
+		}
+		return false
+	})
+
+	result := make([]beacon.Record, len(selected))
+	for i, e := range selected {
+		result[i] = e.record
+	}
+	return result, nil
+}
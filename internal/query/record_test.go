@@ -0,0 +1,117 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+)
+
+var testNow = time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+func testRecords() []beacon.Record {
+	records := beacon.Records([]beacon.State{
+		{ID: "a", Status: beacon.StatusRunning, UpdatedAt: testNow.Add(-time.Minute)},
+		{ID: "b", Status: beacon.StatusWaiting, UpdatedAt: testNow.Add(-10 * time.Minute)},
+		{ID: "c", Status: beacon.StatusWaiting, UpdatedAt: testNow.Add(-time.Hour)},
+		{ID: "d", Status: beacon.StatusWaiting},
+	})
+	records[1].Context = map[string]any{"tmux": map[string]any{"session_name": "api"}}
+	records[2].Context = map[string]any{"tmux": map[string]any{"session_name": "web"}}
+	return records
+}
+
+func ids(records []beacon.Record) string {
+	var s string
+	for _, record := range records {
+		s += record.ID
+	}
+	return s
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		sort   []string
+		want   string
+	}{
+		{"all", "", nil, "abcd"},
+		{"waiting in session api longer than 5m", "status = waiting-for-input and context.tmux.session_name = api and age > 5m", nil, "b"},
+		{"sort by age", "", []string{"age"}, "abcd"},
+		{"sort by age descending", "", []string{"-age"}, "cbad"},
+		{"sort by status then id descending", "", []string{"status", "-id"}, "adcb"},
+		{"sort by context", "", []string{"-context.tmux.session_name"}, "cbad"},
+		{"filter and sort", "status = waiting-for-input", []string{"-updated_at"}, "bcd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filter *Filter
+			if tt.filter != "" {
+				var err error
+				if filter, err = ParseFilter(tt.filter); err != nil {
+					t.Fatalf("ParseFilter() error = %v", err)
+				}
+			}
+			keys, err := ParseSort(tt.sort)
+			if err != nil {
+				t.Fatalf("ParseSort() error = %v", err)
+			}
+			got, err := Select(testRecords(), filter, keys, testNow)
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			if ids(got) != tt.want {
+				t.Errorf("Select() = %s, want %s", ids(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestSelect_FilterError(t *testing.T) {
+	filter, err := ParseFilter("age > soon")
+	if err != nil {
+		t.Fatalf("ParseFilter() error = %v", err)
+	}
+	if _, err := Select(testRecords(), filter, nil, testNow); err == nil {
+		t.Error("Select() expected error, got nil")
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	keys, err := ParseSort([]string{"-age", "+id", "status"})
+	if err != nil {
+		t.Fatalf("ParseSort() error = %v", err)
+	}
+	want := []SortKey{{"age", true}, {"id", false}, {"status", false}}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("keys[%d] = %+v, want %+v", i, keys[i], want[i])
+		}
+	}
+
+	if _, err := ParseSort([]string{"-"}); err == nil {
+		t.Error("ParseSort() expected error for empty key, got nil")
+	}
+}
+
+func TestRecordFields(t *testing.T) {
+	record := testRecords()[1]
+	record.ExpiresAt = testNow.Add(time.Hour)
+	fields, err := RecordFields(record, testNow)
+	if err != nil {
+		t.Fatalf("RecordFields() error = %v", err)
+	}
+	if fields["age"] != 10*time.Minute {
+		t.Errorf("age = %v, want 10m", fields["age"])
+	}
+	if fields["expires_at"] != testNow.Add(time.Hour) {
+		t.Errorf("expires_at = %v", fields["expires_at"])
+	}
+	if v, _ := fields.Lookup("context.tmux.session_name"); v != "api" {
+		t.Errorf("context.tmux.session_name = %v, want api", v)
+	}
+	if _, ok := fields.Lookup("context.tmux.session_name.x"); ok {
+		t.Error("Lookup() through a string should fail")
+	}
+}
@@ -0,0 +1,241 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+)
+
+// Format names an output format for beacon listings.
+type Format string
+
+const (
+	FormatTable  Format = "table"
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatTSV    Format = "tsv"
+)
+
+// Options controls how beacon records are rendered.
+type Options struct {
+	Format Format
+	// Template is a Go text/template applied to each record. It overrides Format.
+	Template string
+	// WithContext adds a location column to the table and tsv formats.
+	WithContext bool
+	// WithProfile adds a leading profile column to the table and tsv formats.
+	WithProfile bool
+	// Color enables ANSI colors in the table format.
+	Color bool
+	// Now is the reference time for relative ages. Defaults to time.Now().
+	Now time.Time
+}
+
+// Records writes records to w in the requested format.
+func Records(w io.Writer, records []beacon.Record, opts Options) error {
+	if opts.Template != "" {
+		return renderTemplate(w, records, opts.Template)
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	switch opts.Format {
+	case FormatTable, "":
+		return renderTable(w, records, opts)
+	case FormatJSON:
+		if records == nil {
+			records = []beacon.Record{}
+		}
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, record := range records {
+			if err := enc.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatTSV:
+		for _, record := range records {
+			fields := []string{record.ID, string(record.Status), record.Message}
+			if opts.WithProfile {
+				fields = append([]string{record.Profile}, fields...)
+			}
+			if opts.WithContext {
+				fields = append(fields, record.Location)
+			}
+			for i, field := range fields {
+				fields[i] = escapeTSV(field)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format: %s", opts.Format)
+	}
+}
+
+// renderTemplate executes text once per record. Fields missing from a
+// record, such as context of a beacon that has none, render as empty strings.
+func renderTemplate(w io.Writer, records []beacon.Record, text string) error {
+	tmpl, err := template.New("list").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, record := range records {
+		buf.Reset()
+		if err := tmpl.Execute(&buf, newTemplateRecord(record)); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateRecord is the record a template sees. Its context values are
+// text, so a missing type or field is the empty string rather than a nil
+// interface, which text/template would print as "<no value>".
+type templateRecord struct {
+	beacon.Record
+	Context map[string]map[string]string
+}
+
+func newTemplateRecord(record beacon.Record) templateRecord {
+	view := templateRecord{Record: record, Context: map[string]map[string]string{}}
+	for typ, value := range record.Context {
+		fields, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		view.Context[typ] = make(map[string]string, len(fields))
+		for name, field := range fields {
+			if field != nil {
+				view.Context[typ][name] = fmt.Sprint(field)
+			}
+		}
+	}
+	return view
+}
+
+func renderTable(w io.Writer, records []beacon.Record, opts Options) error {
+	withLabels := slices.ContainsFunc(records, func(r beacon.Record) bool { return len(r.Labels) > 0 })
+	headers := []string{"ID", "STATUS", "AGE"}
+	if opts.WithProfile {
+		headers = append([]string{"PROFILE"}, headers...)
+	}
+	if opts.WithContext {
+		headers = append(headers, "LOCATION")
+	}
+	if withLabels {
+		headers = append(headers, "LABELS")
+	}
+	t := newTable(append(headers, "MESSAGE")...)
+	for _, record := range records {
+		var row []cell
+		if opts.WithProfile {
+			row = append(row, cell{text: record.Profile})
+		}
+		row = append(row,
+			cell{text: record.ID},
+			cell{text: string(record.Status), color: statusColor(record.Status)},
+			cell{text: age(record.State, opts.Now)},
+		)
+		if opts.WithContext {
+			row = append(row, cell{text: orDash(record.Location)})
+		}
+		if withLabels {
+			row = append(row, cell{text: orDash(record.Labels.String())})
+		}
+		t.addRow(append(row, cell{text: record.Message}))
+	}
+	return t.write(w, opts.Color)
+}
+
+// orDash returns s, or "-" for an empty cell.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// statusColor returns the ANSI color code used for a status in tables.
+func statusColor(status beacon.Status) string {
+	switch status {
+	case beacon.StatusWaiting:
+		return "33"
+	case beacon.StatusBlocked, beacon.StatusFailed:
+		return "31"
+	case beacon.StatusRunning:
+		return "34"
+	case beacon.StatusDone:
+		return "32"
+	}
+	return ""
+}
+
+// age renders the time since the state was last updated, or "-" if unknown.
+func age(state beacon.State, now time.Time) string {
+	if state.UpdatedAt.IsZero() {
+		return "-"
+	}
+	return formatAge(now.Sub(state.UpdatedAt))
+}
+
+// formatAge renders a duration in its largest whole unit, such as "5m" or "2d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "0s"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// SingleLine collapses line breaks and tabs and removes other control
+// characters, such as the escape sequences of agent-supplied messages, so a
+// value fits one line of the terminal.
+func SingleLine(s string) string {
+	return StripControl(strings.Join(strings.Fields(s), " "))
+}
+
+// StripControl removes control characters from s.
+func StripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// escapeTSV escapes characters that would break a tab-separated line.
+func escapeTSV(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
@@ -0,0 +1,287 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+)
+
+var testNow = time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+func testRecords() []beacon.Record {
+	return beacon.Records([]beacon.State{
+		{Version: 1, ID: "agent-1", Status: beacon.StatusWaiting, Message: "Approve?\nline two", CreatedAt: testNow, UpdatedAt: testNow.Add(-5 * time.Minute), EmitCount: 1},
+		{Version: 1, ID: "日本語", Status: beacon.StatusRunning, Message: "作業中\tです", CreatedAt: testNow, UpdatedAt: testNow.Add(-2 * time.Hour), EmitCount: 3},
+	})
+}
+
+func TestRecords_Table(t *testing.T) {
+	var buf bytes.Buffer
+	err := Records(&buf, testRecords(), Options{Format: FormatTable, Now: testNow})
+	if err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+
+	expected := "" +
+		"ID       STATUS             AGE  MESSAGE\n" +
+		"agent-1  waiting-for-input  5m   Approve? line two\n" +
+		"日本語   running            2h   作業中 です\n"
+	if buf.String() != expected {
+		t.Errorf("Records() table =\n%s\nwant\n%s", buf.String(), expected)
+	}
+}
+
+func TestRecords_TableColor(t *testing.T) {
+	var buf bytes.Buffer
+	err := Records(&buf, testRecords()[:1], Options{Format: FormatTable, Color: true, Now: testNow})
+	if err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "\x1b[33mwaiting-for-input\x1b[0m") {
+		t.Errorf("Records() colored table = %q, want yellow status", buf.String())
+	}
+}
+
+func TestRecords_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Records(&buf, nil, Options{Format: FormatJSON}); err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("Records() empty json = %q, want %q", buf.String(), "[]\n")
+	}
+
+	buf.Reset()
+	if err := Records(&buf, testRecords()[:1], Options{Format: FormatJSON}); err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "[\n  {\n    \"version\": 1,\n    \"id\": \"agent-1\",") {
+		t.Errorf("Records() json = %q", buf.String())
+	}
+}
+
+func TestRecords_NDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Records(&buf, testRecords(), Options{Format: FormatNDJSON}); err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Records() ndjson lines = %d, want 2", len(lines))
+	}
+	if !strings.Contains(lines[0], `"message":"Approve?\nline two"`) {
+		t.Errorf("Records() ndjson line = %q", lines[0])
+	}
+}
+
+func TestRecords_TSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Records(&buf, testRecords(), Options{Format: FormatTSV}); err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+
+	expected := "agent-1\twaiting-for-input\tApprove?\\nline two\n" +
+		"日本語\trunning\t作業中\\tです\n"
+	if buf.String() != expected {
+		t.Errorf("Records() tsv = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestRecords_Template(t *testing.T) {
+	var buf bytes.Buffer
+	err := Records(&buf, testRecords(), Options{Format: FormatJSON, Template: "{{.ID}}={{.Status}}"})
+	if err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+
+	expected := "agent-1=waiting-for-input\n日本語=running\n"
+	if buf.String() != expected {
+		t.Errorf("Records() template = %q, want %q", buf.String(), expected)
+	}
+}
+
+func testRecordsWithContext() []beacon.Record {
+	records := testRecords()
+	records[0].Context = map[string]any{
+		"tmux": map[string]any{"session_name": "main", "window_index": float64(1), "pane_index": float64(0), "pane_id": "%3"},
+	}
+	records[0].Location = "main:1.0"
+	return records
+}
+
+func TestRecords_TableWithContext(t *testing.T) {
+	var buf bytes.Buffer
+	err := Records(&buf, testRecordsWithContext(), Options{Format: FormatTable, WithContext: true, Now: testNow})
+	if err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+
+	expected := "" +
+		"ID       STATUS             AGE  LOCATION  MESSAGE\n" +
+		"agent-1  waiting-for-input  5m   main:1.0  Approve? line two\n" +
+		"日本語   running            2h   -         作業中 です\n"
+	if buf.String() != expected {
+		t.Errorf("Records() table =\n%s\nwant\n%s", buf.String(), expected)
+	}
+}
+
+func TestRecords_TableLabels(t *testing.T) {
+	records := testRecords()
+	records[1].Labels = beacon.Labels{"project": "web", "agent": "claude"}
+	var buf bytes.Buffer
+	if err := Records(&buf, records, Options{Format: FormatTable, Now: testNow}); err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+
+	expected := "" +
+		"ID       STATUS             AGE  LABELS                    MESSAGE\n" +
+		"agent-1  waiting-for-input  5m   -                         Approve? line two\n" +
+		"日本語   running            2h   agent=claude,project=web  作業中 です\n"
+	if buf.String() != expected {
+		t.Errorf("Records() table =\n%s\nwant\n%s", buf.String(), expected)
+	}
+}
+
+func TestRecords_TSVWithContext(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Records(&buf, testRecordsWithContext(), Options{Format: FormatTSV, WithContext: true}); err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+
+	expected := "agent-1\twaiting-for-input\tApprove?\\nline two\tmain:1.0\n" +
+		"日本語\trunning\t作業中\\tです\t\n"
+	if buf.String() != expected {
+		t.Errorf("Records() tsv = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestRecords_WithProfile(t *testing.T) {
+	records := testRecords()
+	records[0].Profile = "default"
+	records[1].Profile = "work"
+
+	var buf bytes.Buffer
+	if err := Records(&buf, records, Options{Format: FormatTable, WithProfile: true, Now: testNow}); err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+	expected := "" +
+		"PROFILE  ID       STATUS             AGE  MESSAGE\n" +
+		"default  agent-1  waiting-for-input  5m   Approve? line two\n" +
+		"work     日本語   running            2h   作業中 です\n"
+	if buf.String() != expected {
+		t.Errorf("Records() table =\n%s\nwant\n%s", buf.String(), expected)
+	}
+
+	buf.Reset()
+	if err := Records(&buf, records, Options{Format: FormatTSV, WithProfile: true}); err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+	expected = "default\tagent-1\twaiting-for-input\tApprove?\\nline two\n" +
+		"work\t日本語\trunning\t作業中\\tです\n"
+	if buf.String() != expected {
+		t.Errorf("Records() tsv = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestRecords_JSONWithContext(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Records(&buf, testRecordsWithContext(), Options{Format: FormatNDJSON}); err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if !strings.Contains(lines[0], `"context":{"tmux":{"pane_id":"%3","pane_index":0,"session_name":"main","window_index":1}},"location":"main:1.0"`) {
+		t.Errorf("Records() ndjson with context = %q", lines[0])
+	}
+	if strings.Contains(lines[1], `"context"`) || strings.Contains(lines[1], `"location"`) {
+		t.Errorf("Records() ndjson without context = %q", lines[1])
+	}
+}
+
+func TestRecords_TemplateContext(t *testing.T) {
+	var buf bytes.Buffer
+	err := Records(&buf, testRecordsWithContext(), Options{Template: "{{.ID}}@{{.Context.tmux.session_name}}"})
+	if err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+
+	expected := "agent-1@main\n日本語@\n"
+	if buf.String() != expected {
+		t.Errorf("Records() template = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestRecords_TemplateKeepsMessage(t *testing.T) {
+	records := testRecords()[:1]
+	records[0].Message = "hello <no value> x"
+	var buf bytes.Buffer
+	if err := Records(&buf, records, Options{Template: "{{.Message}}|{{.Context.tmux.pane_id}}|{{.Labels.project}}"}); err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+	if want := "hello <no value> x||\n"; buf.String() != want {
+		t.Errorf("Records() template = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestRecords_TableControlCharacters(t *testing.T) {
+	records := testRecords()[:1]
+	records[0].Message = "\x1b[2Jdone\r\nnext\x07"
+	records[0].Labels = beacon.Labels{"x": "\x1b[31mred"}
+	var buf bytes.Buffer
+	if err := Records(&buf, records, Options{Format: FormatTable, Now: testNow}); err != nil {
+		t.Fatalf("Records() error = %v", err)
+	}
+	if strings.ContainsAny(buf.String(), "\x1b\x07\r") {
+		t.Errorf("Records() table passes control characters through: %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "x=[31mred  [2Jdone next\n") {
+		t.Errorf("Records() table = %q", buf.String())
+	}
+}
+
+func TestSingleLine(t *testing.T) {
+	tests := map[string]string{
+		"a\n\tb  c":          "a b c",
+		"\x1b[31mred\x1b[0m": "[31mred[0m",
+		"bell\x07\u0085next": "bell next",
+		"作業中\u200bです":        "作業中\u200bです",
+	}
+	for input, want := range tests {
+		if got := SingleLine(input); got != want {
+			t.Errorf("SingleLine(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestRecords_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Records(&buf, testRecords(), Options{Format: "xml"}); err == nil {
+		t.Error("Records() expected error for unknown format, got nil")
+	}
+	if err := Records(&buf, testRecords(), Options{Template: "{{.invalid"}); err == nil {
+		t.Error("Records() expected error for invalid template, got nil")
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{-time.Second, "0s"},
+		{42 * time.Second, "42s"},
+		{5 * time.Minute, "5m"},
+		{3 * time.Hour, "3h"},
+		{50 * time.Hour, "2d"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+)
+
+// Summary writes a one-line summary of the beacons needing attention, such
+// as "2 waiting 1 blocked api:2 web:1". With tmux, the counts carry tmux
+// style codes for the status line and nothing is written if no beacon needs
+// attention, so the segment disappears.
+func Summary(w io.Writer, summary beacon.Summary, tmux bool) error {
+	if summary.Total() == 0 {
+		if tmux {
+			return nil
+		}
+		_, err := fmt.Fprintln(w, "0 waiting")
+		return err
+	}
+
+	style := func(s, fg string) string {
+		if !tmux {
+			return s
+		}
+		return "#[fg=" + fg + "]" + s + "#[default]"
+	}
+	var parts []string
+	if summary.Waiting > 0 {
+		parts = append(parts, style(fmt.Sprintf("%d waiting", summary.Waiting), "yellow"))
+	}
+	if summary.Blocked > 0 {
+		parts = append(parts, style(fmt.Sprintf("%d blocked", summary.Blocked), "red"))
+	}
+	for _, session := range slices.Sorted(maps.Keys(summary.Sessions)) {
+		name := session
+		if tmux {
+			name = escapeTmux(session)
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d", name, summary.Sessions[session]))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(parts, " "))
+	return err
+}
+
+// escapeTmux escapes '#' so tmux does not expand it as a format.
+func escapeTmux(s string) string {
+	return strings.ReplaceAll(s, "#", "##")
+}
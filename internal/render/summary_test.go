@@ -0,0 +1,36 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/monochromegane/beacon/internal/beacon"
+)
+
+func TestSummary(t *testing.T) {
+	summary := beacon.Summary{Waiting: 2, Blocked: 1, Sessions: map[string]int{"web": 1, "api": 2}}
+	tests := []struct {
+		name    string
+		summary beacon.Summary
+		tmux    bool
+		want    string
+	}{
+		{"plain", summary, false, "2 waiting 1 blocked api:2 web:1\n"},
+		{"tmux", summary, true, "#[fg=yellow]2 waiting#[default] #[fg=red]1 blocked#[default] api:2 web:1\n"},
+		{"tmux waiting only", beacon.Summary{Waiting: 1, Sessions: map[string]int{"a#1": 1}}, true, "#[fg=yellow]1 waiting#[default] a##1:1\n"},
+		{"plain waiting only", beacon.Summary{Waiting: 1, Sessions: map[string]int{"a#1": 1}}, false, "1 waiting a#1:1\n"},
+		{"plain empty", beacon.Summary{}, false, "0 waiting\n"},
+		{"tmux empty", beacon.Summary{}, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Summary(&buf, tt.summary, tt.tmux); err != nil {
+				t.Fatalf("Summary() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Summary() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package render
+
+import (
+	"io"
+	"strings"
+)
+
+// cell is a table cell with an optional ANSI color code.
+type cell struct {
+	text  string
+	color string
+}
+
+// table aligns cells into columns by display width.
+type table struct {
+	headers []string
+	rows    [][]cell
+}
+
+func newTable(headers ...string) *table {
+	return &table{headers: headers}
+}
+
+// addRow adds a row, reducing each cell to a single line of printable text.
+func (t *table) addRow(row []cell) {
+	for i := range row {
+		row[i].text = SingleLine(row[i].text)
+	}
+	t.rows = append(t.rows, row)
+}
+
+// write renders the table. Colors are applied after padding so escape
+// sequences do not affect alignment. The last column is not padded.
+func (t *table) write(w io.Writer, color bool) error {
+	widths := make([]int, len(t.headers))
+	for i, header := range t.headers {
+		widths[i] = Width(header)
+	}
+	for _, row := range t.rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], Width(c.text))
+		}
+	}
+
+	header := make([]cell, len(t.headers))
+	for i, h := range t.headers {
+		header[i] = cell{text: h}
+	}
+	for _, row := range append([][]cell{header}, t.rows...) {
+		var b strings.Builder
+		for i, c := range row {
+			text := c.text
+			if i < len(row)-1 {
+				text = Pad(text, widths[i])
+			}
+			if color && c.color != "" {
+				text = "\x1b[" + c.color + "m" + text + "\x1b[0m"
+			}
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(text)
+		}
+		b.WriteString("\n")
+		if _, err := io.WriteString(w, strings.TrimRight(b.String(), " \n")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package render
+
+import "testing"
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"日本語", 6},
+		{"ｱｲｳ", 3},
+		{"ＡＢ", 4},
+		{"한글", 4},
+		{"é", 1},
+		{"🚀", 2},
+		{"a\tb", 2},
+	}
+	for _, tt := range tests {
+		if got := Width(tt.s); got != tt.want {
+			t.Errorf("Width(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"abcdef", 3, "abc"},
+		{"abc", 5, "abc"},
+		{"日本語", 4, "日本"},
+		{"日本語", 5, "日本"},
+		{"a日本", 2, "a"},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.s, tt.width); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestPad(t *testing.T) {
+	if got := Pad("日本", 6); got != "日本  " {
+		t.Errorf("Pad() = %q, want %q", got, "日本  ")
+	}
+	if got := Pad("abcdef", 3); got != "abcdef" {
+		t.Errorf("Pad() = %q, want %q", got, "abcdef")
+	}
+}
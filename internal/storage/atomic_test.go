@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "test123")
+
+	if err := WriteFileAtomic(path, []byte("first"), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte("second"), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(content) != "second" {
+		t.Errorf("WriteFileAtomic() content = %q, want %q", string(content), "second")
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		t.Errorf("WriteFileAtomic() left %d entries, want 1", len(entries))
+	}
+}
+
+func TestWriteFileAtomic_MissingDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "test123")
+
+	if err := WriteFileAtomic(path, []byte("data"), 0644); err == nil {
+		t.Error("WriteFileAtomic() expected error for missing directory, got nil")
+	}
+}
+
+func TestIsHidden(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{".lock", true},
+		{".tmp-test123-42", true},
+		{"test123", false},
+		{"test123.json", false},
+	}
+	for _, tt := range tests {
+		if got := IsHidden(tt.name); got != tt.want {
+			t.Errorf("IsHidden(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRemoveStaleTemp(t *testing.T) {
+	tmpDir := t.TempDir()
+	stale := filepath.Join(tmpDir, ".tmp-stale-1")
+	fresh := filepath.Join(tmpDir, ".tmp-fresh-1")
+	regular := filepath.Join(tmpDir, "test123")
+	for _, path := range []string{stale, fresh, regular} {
+		os.WriteFile(path, []byte("data"), 0644)
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(stale, old, old)
+	os.Chtimes(regular, old, old)
+
+	if err := RemoveStaleTemp(tmpDir, time.Minute); err != nil {
+		t.Fatalf("RemoveStaleTemp() error = %v", err)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("RemoveStaleTemp() stale temp file still exists")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("RemoveStaleTemp() removed fresh temp file: %v", err)
+	}
+	if _, err := os.Stat(regular); err != nil {
+		t.Errorf("RemoveStaleTemp() removed regular file: %v", err)
+	}
+}
+
+func TestRemoveStaleTemp_NonExistentDir(t *testing.T) {
+	if err := RemoveStaleTemp("/nonexistent/path", time.Minute); err != nil {
+		t.Errorf("RemoveStaleTemp() error = %v, want nil", err)
+	}
+}
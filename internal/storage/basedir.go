@@ -1,12 +1,30 @@
 package storage
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 )
 
-// ResolveBaseDir returns the base directory for beacon cache files.
+// DefaultProfile is the profile whose beacons live in the base directory itself.
+const DefaultProfile = "default"
+
+// profilesDir is the hidden directory in the base directory holding one
+// directory per named profile.
+const profilesDir = ".profiles"
+
+// ErrInvalidProfile is returned for profile names that cannot be used.
+var ErrInvalidProfile = errors.New("invalid profile")
+
+// ResolveBaseDir returns the base directory for beacon cache files:
+// $BEACON_DIR if set, or else beacon in the user cache directory.
 func ResolveBaseDir() (string, error) {
+	if dir := os.Getenv("BEACON_DIR"); dir != "" {
+		return dir, nil
+	}
 	if xdgCache := os.Getenv("XDG_CACHE_HOME"); xdgCache != "" {
 		return filepath.Join(xdgCache, "beacon"), nil
 	}
@@ -16,3 +34,63 @@ func ResolveBaseDir() (string, error) {
 	}
 	return filepath.Join(userCache, "beacon"), nil
 }
+
+// Location selects the directory beacon and context files are stored in.
+// The zero Location is the default profile in the resolved base directory.
+type Location struct {
+	// BaseDir replaces the directory returned by ResolveBaseDir.
+	BaseDir string
+	// Profile names a separate namespace of beacons. Empty means DefaultProfile.
+	Profile string
+}
+
+// Dir returns the directory holding the beacons of the location.
+func (l Location) Dir() (string, error) {
+	baseDir := l.BaseDir
+	if baseDir == "" {
+		var err error
+		if baseDir, err = ResolveBaseDir(); err != nil {
+			return "", err
+		}
+	}
+	if l.Profile == "" || l.Profile == DefaultProfile {
+		return baseDir, nil
+	}
+	if err := validateProfile(l.Profile); err != nil {
+		return "", err
+	}
+	return filepath.Join(baseDir, profilesDir, l.Profile), nil
+}
+
+// Profiles returns the default profile followed by the named profiles that
+// have a directory in the base directory of l, sorted by name.
+func (l Location) Profiles() ([]string, error) {
+	baseDir := l.BaseDir
+	if baseDir == "" {
+		var err error
+		if baseDir, err = ResolveBaseDir(); err != nil {
+			return nil, err
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(baseDir, profilesDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && validateProfile(entry.Name()) == nil && entry.Name() != DefaultProfile {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// validateProfile accepts names made of ASCII letters, digits, '-' and '_',
+// so a profile always maps to a single directory.
+func validateProfile(name string) error {
+	if name == "" || strings.ContainsFunc(name, func(r rune) bool { return r >= 0x80 || !isSafeFilenameByte(byte(r)) }) {
+		return fmt.Errorf("%w %q: use letters, digits, '-' and '_'", ErrInvalidProfile, name)
+	}
+	return nil
+}
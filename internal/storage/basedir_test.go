@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestResolveBaseDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/cache")
+	t.Setenv("BEACON_DIR", "")
+	if got, err := ResolveBaseDir(); err != nil || got != filepath.Join("/cache", "beacon") {
+		t.Errorf("ResolveBaseDir() = %q, %v", got, err)
+	}
+	t.Setenv("BEACON_DIR", "/srv/beacons")
+	if got, err := ResolveBaseDir(); err != nil || got != "/srv/beacons" {
+		t.Errorf("ResolveBaseDir() with BEACON_DIR = %q, %v", got, err)
+	}
+}
+
+func TestLocation_Dir(t *testing.T) {
+	t.Setenv("BEACON_DIR", "/env")
+	tests := []struct {
+		loc  Location
+		want string
+	}{
+		{Location{}, "/env"},
+		{Location{Profile: "work"}, filepath.Join("/env", ".profiles", "work")},
+		{Location{BaseDir: "/base"}, "/base"},
+		{Location{BaseDir: "/base", Profile: DefaultProfile}, "/base"},
+		{Location{BaseDir: "/base", Profile: "side_project-2"}, filepath.Join("/base", ".profiles", "side_project-2")},
+	}
+	for _, tt := range tests {
+		if got, err := tt.loc.Dir(); err != nil || got != tt.want {
+			t.Errorf("%+v.Dir() = %q, %v, want %q", tt.loc, got, err, tt.want)
+		}
+	}
+
+	for _, profile := range []string{"../work", "a/b", ".hidden", "wörk"} {
+		if _, err := (Location{BaseDir: "/base", Profile: profile}).Dir(); !errors.Is(err, ErrInvalidProfile) {
+			t.Errorf("Dir() with profile %q error = %v, want ErrInvalidProfile", profile, err)
+		}
+	}
+}
+
+func TestLocation_Profiles(t *testing.T) {
+	baseDir := t.TempDir()
+	loc := Location{BaseDir: baseDir}
+	if got, err := loc.Profiles(); err != nil || !slices.Equal(got, []string{DefaultProfile}) {
+		t.Errorf("Profiles() without profiles = %v, %v", got, err)
+	}
+
+	for _, name := range []string{"work", "home", ".tmp-x"} {
+		if err := os.MkdirAll(filepath.Join(baseDir, ".profiles", name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, ".profiles", "file"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := loc.Profiles(); err != nil || !slices.Equal(got, []string{DefaultProfile, "home", "work"}) {
+		t.Errorf("Profiles() = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidID is returned when a beacon ID cannot be used.
+var ErrInvalidID = errors.New("invalid beacon id")
+
+// maxFilenameLen is the longest encoded ID that still fits a 255-byte file
+// name once the ".json" suffix of the context file is appended.
+const maxFilenameLen = 250
+
+// ID is a validated beacon identifier.
+// Any printable UTF-8 string is accepted, including slashes, colons and URLs;
+// Filename provides the encoding used to store it on disk.
+type ID string
+
+// ParseID validates s and returns it as an ID.
+func ParseID(s string) (ID, error) {
+	if s == "" {
+		return "", fmt.Errorf("%w: must not be empty", ErrInvalidID)
+	}
+	if !utf8.ValidString(s) {
+		return "", fmt.Errorf("%w %q: must be valid UTF-8", ErrInvalidID, s)
+	}
+	if strings.ContainsFunc(s, unicode.IsControl) {
+		return "", fmt.Errorf("%w %q: must not contain control characters", ErrInvalidID, s)
+	}
+	id := ID(s)
+	if len(id.Filename()) > maxFilenameLen {
+		return "", fmt.Errorf("%w %q: too long", ErrInvalidID, s)
+	}
+	return id, nil
+}
+
+// String returns the ID as given by the user.
+func (id ID) String() string {
+	return string(id)
+}
+
+// Filename returns the reversible file name encoding of the ID.
+// ASCII letters, digits, '-' and '_' are kept as is and every other byte is
+// percent-encoded, so the result never contains path separators or dots.
+func (id ID) Filename() string {
+	var b strings.Builder
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if isSafeFilenameByte(c) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// DecodeFilename reverses Filename.
+// A name that Filename would not have produced, such as "%1" or "my.session",
+// belongs to a file written before IDs were encoded and is taken as the
+// literal ID.
+func DecodeFilename(name string) (ID, error) {
+	if id, ok := unescape(name); ok && id.Filename() == name {
+		return id, nil
+	}
+	return ParseID(name)
+}
+
+// LegacyFilename returns the name a file for the ID had before IDs were
+// encoded, which is the ID itself. It reports false if that name is the
+// same as Filename, could not have been a file in the base directory, or is
+// the encoded name of another ID.
+func (id ID) LegacyFilename() (string, bool) {
+	name := string(id)
+	if name == id.Filename() || IsHidden(name) || strings.ContainsAny(name, `/\`) || len(name) > maxFilenameLen {
+		return "", false
+	}
+	if other, ok := unescape(name); ok && other.Filename() == name {
+		return "", false
+	}
+	return name, true
+}
+
+// unescape decodes the percent escapes of name.
+func unescape(name string) (ID, bool) {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+2 >= len(name) {
+			return "", false
+		}
+		hi, ok1 := unhex(name[i+1])
+		lo, ok2 := unhex(name[i+2])
+		if !ok1 || !ok2 {
+			return "", false
+		}
+		b.WriteByte(hi<<4 | lo)
+		i += 2
+	}
+	id, err := ParseID(b.String())
+	return id, err == nil
+}
+
+func isSafeFilenameByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_'
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
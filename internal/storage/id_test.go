@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseID(t *testing.T) {
+	valid := []string{
+		"test123",
+		"session-abc_1",
+		"../../something",
+		"foo.json",
+		"https://example.com/agents/1?x=y",
+		"claude:1234/5678",
+		"日本語",
+	}
+	for _, s := range valid {
+		id, err := ParseID(s)
+		if err != nil {
+			t.Errorf("ParseID(%q) error = %v", s, err)
+			continue
+		}
+		if id.String() != s {
+			t.Errorf("ParseID(%q) = %q", s, id)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"line\nbreak",
+		"tab\there",
+		"\xff\xfe",
+		strings.Repeat("/", 100),
+	}
+	for _, s := range invalid {
+		if _, err := ParseID(s); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("ParseID(%q) error = %v, want ErrInvalidID", s, err)
+		}
+	}
+}
+
+func TestID_Filename(t *testing.T) {
+	tests := []struct {
+		id   ID
+		want string
+	}{
+		{"test123", "test123"},
+		{"session-abc_1", "session-abc_1"},
+		{"../../etc", "%2E%2E%2F%2E%2E%2Fetc"},
+		{"foo.json", "foo%2Ejson"},
+		{"a:b", "a%3Ab"},
+		{"100%", "100%25"},
+		{"é", "%C3%A9"},
+	}
+	for _, tt := range tests {
+		if got := tt.id.Filename(); got != tt.want {
+			t.Errorf("ID(%q).Filename() = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeFilename_RoundTrip(t *testing.T) {
+	ids := []ID{"test123", "../../etc", "foo.json", "https://example.com/a?b=c#d", "100%", "日本語 space"}
+	for _, id := range ids {
+		got, err := DecodeFilename(id.Filename())
+		if err != nil {
+			t.Errorf("DecodeFilename(%q) error = %v", id.Filename(), err)
+			continue
+		}
+		if got != id {
+			t.Errorf("DecodeFilename(%q) = %q, want %q", id.Filename(), got, id)
+		}
+	}
+}
+
+func TestDecodeFilename_Legacy(t *testing.T) {
+	got, err := DecodeFilename("session.1")
+	if err != nil {
+		t.Fatalf("DecodeFilename() error = %v", err)
+	}
+	if got != "session.1" {
+		t.Errorf("DecodeFilename() = %q, want %q", got, "session.1")
+	}
+}
+
+func TestDecodeFilename_LiteralLegacyNames(t *testing.T) {
+	// Names that Filename would not produce are the literal IDs of files
+	// written before IDs were encoded.
+	for _, name := range []string{"%1", "my.session", "abc%", "abc%2", "abc%zz", "%0A", "a%41"} {
+		got, err := DecodeFilename(name)
+		if err != nil {
+			t.Errorf("DecodeFilename(%q) error = %v", name, err)
+			continue
+		}
+		if string(got) != name {
+			t.Errorf("DecodeFilename(%q) = %q, want the literal name", name, got)
+		}
+	}
+}
+
+func TestDecodeFilename_Invalid(t *testing.T) {
+	for _, name := range []string{"abc\n", "\xff"} {
+		if _, err := DecodeFilename(name); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("DecodeFilename(%q) error = %v, want ErrInvalidID", name, err)
+		}
+	}
+}
+
+func TestID_LegacyFilename(t *testing.T) {
+	tests := []struct {
+		id     ID
+		want   string
+		wantOK bool
+	}{
+		{"%1", "%1", true},
+		{"my.session", "my.session", true},
+		{"test123", "", false},
+		{"a/b", "", false},
+		{".hidden", "", false},
+		// "x%2E" is where the ID "x." is stored now.
+		{"x%2E", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.id.LegacyFilename()
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ID(%q).LegacyFilename() = %q, %v, want %q, %v", tt.id, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// lockFileName is the advisory lock file shared by all writers of a base directory.
+const lockFileName = ".lock"
+
+// Lock is an exclusive advisory lock on a base directory.
+type Lock struct {
+	file *os.File
+}
+
+// LockDir acquires an exclusive lock on dir, blocking until it is available.
+// The lock is held across processes and must be released with Unlock.
+func LockDir(dir string) (*Lock, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, lockFileName), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Lock{file: f}, nil
+}
+
+// Unlock releases the lock.
+func (l *Lock) Unlock() error {
+	if err := unlockFile(l.file); err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
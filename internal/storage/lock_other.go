@@ -0,0 +1,37 @@
+//go:build !(darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd)
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// staleLockAge is how long a sentinel file may exist before it is
+// considered abandoned by a crashed process.
+const staleLockAge = 10 * time.Second
+
+// lockFile emulates an exclusive lock with a sentinel file created
+// with O_EXCL on platforms without flock(2).
+func lockFile(f *os.File) error {
+	sentinel := f.Name() + ".held"
+	for {
+		s, err := os.OpenFile(sentinel, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			return s.Close()
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return err
+		}
+		if info, err := os.Stat(sentinel); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(sentinel)
+			continue
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func unlockFile(f *os.File) error {
+	return os.Remove(f.Name() + ".held")
+}
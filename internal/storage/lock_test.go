@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockDir(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	lock, err := LockDir(tmpDir)
+	if err != nil {
+		t.Fatalf("LockDir() error = %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	// The lock can be acquired again once released.
+	lock, err = LockDir(tmpDir)
+	if err != nil {
+		t.Fatalf("LockDir() error = %v", err)
+	}
+	lock.Unlock()
+}
+
+func TestLockDir_MutualExclusion(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	holders, maxHolders := 0, 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, err := LockDir(tmpDir)
+			if err != nil {
+				t.Errorf("LockDir() error = %v", err)
+				return
+			}
+			mu.Lock()
+			holders++
+			if holders > maxHolders {
+				maxHolders = holders
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			holders--
+			mu.Unlock()
+			lock.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if maxHolders != 1 {
+		t.Errorf("LockDir() concurrent holders = %d, want 1", maxHolders)
+	}
+}
@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// pollInterval is how often directories are rescanned on platforms
+// without a native file change notification API.
+const pollInterval = time.Second
+
+// WatchDir reports changes to the entries of dir on the returned channel.
+// Changes to hidden files are ignored. Notifications are coalesced, so a
+// receiver must rescan the directory rather than count signals.
+// The channel is closed when ctx is done or the directory can no longer be watched.
+func WatchDir(ctx context.Context, dir string) (<-chan struct{}, error) {
+	return watchDir(ctx, dir)
+}
+
+// signal performs a non-blocking send on a coalescing channel.
+func signal(ch chan<- struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
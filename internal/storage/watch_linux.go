@@ -0,0 +1,78 @@
+//go:build linux
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO |
+	syscall.IN_MOVED_FROM | syscall.IN_DELETE | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// watchDir watches dir with inotify.
+func watchDir(ctx context.Context, dir string) (<-chan struct{}, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	if _, err := syscall.InotifyAddWatch(fd, dir, inotifyMask); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+	// A non-blocking descriptor is registered with the runtime poller,
+	// so closing the file unblocks a pending Read.
+	f := os.NewFile(uintptr(fd), "inotify")
+
+	ch := make(chan struct{}, 1)
+	go func() {
+		<-ctx.Done()
+		f.Close()
+	}()
+	go func() {
+		defer close(ch)
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			changed, gone := parseInotifyEvents(buf[:n])
+			if changed {
+				signal(ch)
+			}
+			if gone {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// parseInotifyEvents reports whether buf contains a change to a visible
+// entry and whether the watched directory itself went away.
+func parseInotifyEvents(buf []byte) (changed bool, gone bool) {
+	for offset := 0; offset+syscall.SizeofInotifyEvent <= len(buf); {
+		event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		nameStart := offset + syscall.SizeofInotifyEvent
+		nameEnd := nameStart + int(event.Len)
+		if nameEnd > len(buf) {
+			break
+		}
+		name := string(bytes.TrimRight(buf[nameStart:nameEnd], "\x00"))
+		offset = nameEnd
+
+		switch {
+		case event.Mask&(syscall.IN_DELETE_SELF|syscall.IN_MOVE_SELF|syscall.IN_IGNORED) != 0:
+			gone = true
+		case event.Mask&syscall.IN_Q_OVERFLOW != 0:
+			changed = true
+		case name != "" && !IsHidden(name):
+			changed = true
+		}
+	}
+	return changed, gone
+}
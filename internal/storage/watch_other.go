@@ -0,0 +1,65 @@
+//go:build !linux
+
+package storage
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+)
+
+// watchDir polls dir for changes to its entries.
+func watchDir(ctx context.Context, dir string) (<-chan struct{}, error) {
+	prev, err := dirSignature(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan struct{}, 1)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			cur, err := dirSignature(dir)
+			if err != nil {
+				return
+			}
+			if cur != prev {
+				prev = cur
+				signal(ch)
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// dirSignature summarizes the names, sizes and modification times of the
+// visible entries of dir.
+func dirSignature(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var sig []byte
+	for _, entry := range entries {
+		if IsHidden(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		sig = append(sig, entry.Name()...)
+		sig = append(sig, info.ModTime().Format(time.RFC3339Nano)...)
+		sig = strconv.AppendInt(sig, info.Size(), 10)
+		sig = append(sig, 0)
+	}
+	return string(sig), nil
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func waitSignal(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+	select {
+	case _, ok := <-ch:
+		if !ok {
+			t.Fatal("WatchDir() channel closed unexpectedly")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchDir() no change signaled")
+	}
+}
+
+func TestWatchDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := WatchDir(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("WatchDir() error = %v", err)
+	}
+
+	if err := WriteFileAtomic(filepath.Join(tmpDir, "test123"), []byte("data"), 0644); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+	waitSignal(t, ch)
+
+	os.Remove(filepath.Join(tmpDir, "test123"))
+	waitSignal(t, ch)
+}
+
+func TestWatchDir_Cancel(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch, err := WatchDir(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("WatchDir() error = %v", err)
+	}
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("WatchDir() channel not closed after cancel")
+		}
+	}
+}
+
+func TestWatchDir_NonExistentDir(t *testing.T) {
+	if _, err := WatchDir(context.Background(), "/nonexistent/path"); err == nil {
+		t.Error("WatchDir() expected error for non-existent directory, got nil")
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -10,7 +11,15 @@ import (
 func main() {
 	cli := cmd.NewCLI()
 	if err := cli.Execute(os.Args[1:]); err != nil {
+		code := 1
+		var exitErr *cmd.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.Err == nil {
+				os.Exit(exitErr.Code)
+			}
+			code = exitErr.Code
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(code)
 	}
 }